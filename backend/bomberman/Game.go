@@ -1,7 +1,6 @@
 package bomberman
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
 	"time"
@@ -9,8 +8,6 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-
-
 func (g *GameBoard) CanCreateNewPlayer() bool {
 	if 0 < g.NumberOfPlayers+1 && g.NumberOfPlayers+1 <= MaxNumberOfPlayers && g.GameState == "lobby" {
 		return true
@@ -23,17 +20,11 @@ func (g *GameBoard) FindColor() string {
 }
 
 func (g *GameBoard) FindStartRowLocation() int {
-	if g.NumberOfPlayers+1 == 1 || g.NumberOfPlayers+1 == 2 {
-		return 0
-	}
-	return NumberOfRows - 1
+	return g.Spawns[g.NumberOfPlayers][0]
 }
 
 func (g *GameBoard) FindStartColLocation() int {
-	if g.NumberOfPlayers+1 == 1 || g.NumberOfPlayers+1 == 3 {
-		return 0
-	}
-	return NumberOfColumns - 1
+	return g.Spawns[g.NumberOfPlayers][1]
 }
 
 // FindInnerCell determines which cell the player is entering based on their pixel position
@@ -55,7 +46,7 @@ func (g *GameBoard) FindInnerCell(axis byte, direction byte, location int, playe
 		leftBorder := currentCol * cellSize
 
 		if direction == 'r' { // Moving right
-			if location >= rightBorder && currentCol < NumberOfColumns-1 {
+			if location >= rightBorder && currentCol < g.Columns-1 {
 				return currentCol + 1
 			}
 		} else if direction == 'l' { // Moving left
@@ -70,7 +61,7 @@ func (g *GameBoard) FindInnerCell(axis byte, direction byte, location int, playe
 		topBorder := currentRow * cellSize
 
 		if direction == 'd' { // Moving down
-			if location >= bottomBorder && currentRow < NumberOfRows-1 {
+			if location >= bottomBorder && currentRow < g.Rows-1 {
 				return currentRow + 1
 			}
 		} else if direction == 'u' { // Moving up
@@ -94,8 +85,8 @@ func (g *GameBoard) FindGridBorderLocation(borderName byte, playerIndex int) int
 	cellSize := int(g.CellSize)
 
 	// Clamp row and column to valid ranges
-	row := Clamp(player.Row, 0, NumberOfRows-1)
-	col := Clamp(player.Column, 0, NumberOfColumns-1)
+	row := Clamp(player.Row, 0, g.Rows-1)
+	col := Clamp(player.Column, 0, g.Columns-1)
 
 	switch borderName {
 	case 'u': // Top border of current cell
@@ -131,15 +122,15 @@ func (g *GameBoard) RandomStart() {
 
 	// Define safe zones around each player start (row, col) + adjacent cells
 	safeZones := map[int][][2]int{
-		0: {{0, 0}, {0, 1}, {1, 0}},                                                                                                    // Top-left
-		1: {{0, NumberOfColumns - 1}, {0, NumberOfColumns - 2}, {1, NumberOfColumns - 1}},                                              // Top-right
-		2: {{NumberOfRows - 1, 0}, {NumberOfRows - 2, 0}, {NumberOfRows - 1, 1}},                                                       // Bottom-left
-		3: {{NumberOfRows - 1, NumberOfColumns - 1}, {NumberOfRows - 2, NumberOfColumns - 1}, {NumberOfRows - 1, NumberOfColumns - 2}}, // Bottom-right
+		0: {{0, 0}, {0, 1}, {1, 0}},                                                                // Top-left
+		1: {{0, g.Columns - 1}, {0, g.Columns - 2}, {1, g.Columns - 1}},                            // Top-right
+		2: {{g.Rows - 1, 0}, {g.Rows - 2, 0}, {g.Rows - 1, 1}},                                     // Bottom-left
+		3: {{g.Rows - 1, g.Columns - 1}, {g.Rows - 2, g.Columns - 1}, {g.Rows - 1, g.Columns - 2}}, // Bottom-right
 	}
 
 	// Step 1: Fill grid with walls
-	for row := 0; row < NumberOfRows; row++ {
-		for col := 0; col < NumberOfColumns; col++ {
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Columns; col++ {
 			var cell string
 
 			// Step 1.1: Place indestructible wall at even-even positions
@@ -147,7 +138,7 @@ func (g *GameBoard) RandomStart() {
 				cell = "W"
 			} else {
 				// Step 1.2: Randomly place destructible walls (30% chance)
-				if rand.Float64() < 0.3 {
+				if g.rng.Float64() < 0.3 {
 					cell = "D"
 				}
 			}
@@ -156,62 +147,167 @@ func (g *GameBoard) RandomStart() {
 		}
 	}
 
-	// Step 2: Clear player spawn zones
+	// Step 2: Clear player spawn zones, the first cell of each zone is the spawn point
+	g.Spawns = make([][2]int, MaxNumberOfPlayers)
 	for i := 0; i < MaxNumberOfPlayers; i++ {
 		for _, pos := range safeZones[i] {
 			row, col := pos[0], pos[1]
 			g.Panel[row][col] = "" // empty cell
 		}
+		g.Spawns[i] = safeZones[i][0]
+	}
+
+	g.PrintPanel()
+}
+
+// BuildBoard lays out a fresh board: the room's map if it has one, a random one otherwise.
+func (g *GameBoard) BuildBoard() {
+	if g.Map != nil {
+		g.ApplyMap(g.Map)
+		return
 	}
+	g.RandomStart()
+}
 
-	// Print panel to console
-	fmt.Println("Game board:")
+// PrintPanel logs the board.
+func (g *GameBoard) PrintPanel() {
+	board := "Game board:"
 	for _, line := range g.Panel {
-		lineForPrint := ""
+		board += "\n"
 		for _, char := range line {
 			if char == "" {
 				char = "·"
 			}
-			lineForPrint += char
+			board += char
 		}
-		fmt.Println(lineForPrint)
 	}
+	log.Println(board)
 }
 
+// SendPlayerAccepted confirms the slot to its player, with the UUID that resumes it.
 func (g *GameBoard) SendPlayerAccepted(playerIndex int) {
+	g.Mu.Lock()
+	if !g.validPlayer(playerIndex) {
+		g.Mu.Unlock()
+		return
+	}
+	uuid := g.Players[playerIndex].UUID
+	g.Mu.Unlock()
 	msg := map[string]interface{}{
 		"type":  "PlayerAccepted",
 		"index": playerIndex,
+		"uuid":  uuid,
 	}
+	g.SendMsgToPlayer(msg, playerIndex)
+}
 
-	conn, ok := g.PlayersConnections[playerIndex]
-	if !ok {
-		log.Printf("error: no connection for player index %d", playerIndex)
-		return
+// InitGame creates an empty board. A seed of 0 picks a random one.
+func InitGame(id string, seed int64) *GameBoard {
+	g := &GameBoard{
+		ID:                   id,
+		CreatedAt:            time.Now(),
+		IsStarted:            false,
+		GameState:            "lobby",
+		StopCountdown:        false,
+		CellSize:             CellSize,
+		NumberOfPlayers:      0,
+		PlayersConnections:   make(map[int]*websocket.Conn),
+		SpectatorConnections: make(map[int]*websocket.Conn),
+		BroadcastChannel:     make(chan interface{}, 100),
+		powerupChosen:        make(map[string]int),
+		TickRate:             DefaultTickRate,
+		Mode:                 ModeFreeForAll,
+		quit:                 make(chan struct{}),
+	}
+	g.Rules = DefaultRules()
+	g.Stats = NewMatchStats()
+	g.SetSize(DefaultNumberOfRows, DefaultNumberOfColumns)
+	g.SetSeed(seed)
+	return g
+}
+
+// SetSize gives the board new dimensions and an empty panel of that size.
+func (g *GameBoard) SetSize(rows, columns int) {
+	g.Rows = rows
+	g.Columns = columns
+	g.Panel = NewPanel(rows, columns)
+}
+
+// NewPanel allocates an empty grid of the given size.
+func NewPanel(rows, columns int) [][]string {
+	panel := make([][]string, rows)
+	for row := range panel {
+		panel[row] = make([]string, columns)
 	}
+	return panel
+}
 
-	err := conn.WriteJSON(msg)
-	if err != nil {
-		log.Printf("error writing json to player %d: %v", playerIndex, err)
-		conn.Close()
+// copyPanel returns a deep copy of a grid, safe to hand to the broadcaster.
+func copyPanel(panel [][]string) [][]string {
+	copied := make([][]string, len(panel))
+	for row := range panel {
+		copied[row] = append([]string(nil), panel[row]...)
 	}
+	return copied
 }
 
-func InitGame() *GameBoard {
-	g := &GameBoard{
-		IsStarted:          false,
-		GameState:          "lobby",
-		StopCountdown:      false,
-		CellSize:           CellSize,
-		NumberOfPlayers:    0,
-		PlayersConnections: make(map[int]*websocket.Conn),
-		BroadcastChannel:   make(chan interface{}, 100),
-		powerupChosen:      make(map[string]int),
+// SetSeed resets the board's random source, which drives map generation and powerup drops.
+// The same seed gives the same board and the same drop sequence. A seed of 0 picks a random one.
+func (g *GameBoard) SetSeed(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
-	g.StartBombWatcher()
-	return g
+	g.Seed = seed
+	g.rng = rand.New(rand.NewSource(seed))
+	log.Printf("Room %s: using seed %d\n", g.ID, seed)
+}
+
+// Start launches the goroutines that drive this board: the broadcaster and the game loop.
+func (g *GameBoard) Start() {
+	go g.StartBroadcaster()
+	g.StartGameLoop()
+}
+
+// Close disconnects every player and stops the board's goroutines. It is safe to call more than once.
+func (g *GameBoard) Close() {
+	g.closeOnce.Do(func() {
+		g.Mu.Lock()
+		for _, conn := range g.PlayersConnections {
+			conn.Close()
+		}
+		g.PlayersConnections = make(map[int]*websocket.Conn)
+		for _, conn := range g.SpectatorConnections {
+			conn.Close()
+		}
+		g.SpectatorConnections = make(map[int]*websocket.Conn)
+		g.stopRecording()
+		g.Mu.Unlock()
+		close(g.quit)
+	})
+}
+
+func (g *GameBoard) Closed() bool {
+	select {
+	case <-g.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// finish hands the board over to OnFinished after a match, or resets it for a new one.
+func (g *GameBoard) finish() {
+	if g.OnFinished != nil {
+		g.OnFinished(g)
+		return
+	}
+	g.ResetGame()
 }
 func (g *GameBoard) CheckGameEnd() {
+	if g.isTeamMode() {
+		g.checkTeamGameEnd()
+		return
+	}
 	livePlayers := 0
 	var lastPlayer Player
 	for _, player := range g.Players {
@@ -225,55 +321,76 @@ func (g *GameBoard) CheckGameEnd() {
 	if livePlayers <= 1 && g.IsStarted {
 		switch livePlayers {
 		case 1:
-			g.IsStarted = false
-			msg := map[string]interface{}{
+			log.Printf("Game over! Winner is player %d\n", lastPlayer.Index)
+			g.endMatch(map[string]interface{}{
 				"type":   "GameState",
 				"state":  "GameOver",
 				"winner": lastPlayer.Index,
 				"player": lastPlayer,
-			}
-			g.SendMsgToChannel(msg, -1)
-			log.Printf("Game over! Winner is player %d\n", lastPlayer.Index)
+			}, []int{lastPlayer.Index})
 		case 0:
-			g.IsStarted = false
-			msg := map[string]interface{}{
+			log.Printf("Game over! It's a draw.")
+			g.endMatch(map[string]interface{}{
 				"type":   "GameState",
 				"state":  "GameOver",
 				"winner": -1,
-			}
-			g.SendMsgToChannel(msg, -1)
-			log.Printf("Game over! It's a draw.")
+			}, nil)
 		}
-		go func() {
-			time.Sleep(1 * time.Second)
-			log.Println("Resetting the game")
-			g.ResetGame()
-		}()
 	}
 }
 
+// endMatch announces the end of the match, saves the results of the winners and the
+// other players, and finishes the room shortly after. Must be called with g.Mu held.
+func (g *GameBoard) endMatch(msg map[string]interface{}, winners []int) {
+	g.IsStarted = false
+	metrics.countMatch(g.Mode)
+	msg["match"] = g.matchID()
+	g.emit(msg)
+	g.saveTranscript(msg["match"].(string))
+	records := g.matchRecords(winners)
+	go func() {
+		saveMatch(g.Profiles, records)
+		time.Sleep(1 * time.Second)
+		log.Printf("Match in room %s finished\n", g.ID)
+		g.finish()
+	}()
+}
+
 func (g *GameBoard) ResetGame() {
 	g.Mu.Lock()
+	g.stopRecording()
 	for conn := range g.PlayersConnections {
 		g.PlayersConnections[conn].Close()
 	}
 	g.Players = []Player{}
+	g.bots = nil
+	g.Stats = NewMatchStats()
+	g.StartedAt = time.Time{}
+	g.suddenDeathCells = nil
 	g.Bombs = []Bomb{}
 	g.Powerups = []Powerup{}
 	g.PendingRespawns = []PlayerRespawn{}
+	g.inputs = nil
+	g.pendingUpdates = nil
+	g.chatLog = nil
+	g.matchChat = nil
+	g.chatMutes = nil
+	g.chatLimits = nil
+	g.deltaBase = nil
 	g.NumberOfPlayers = 0
 	g.IsStarted = false
+	g.countdownGen++
+	g.countingDown = false
+	g.StopCountdown = false
 	g.ExplodedCells = []ExplodedCellInfo{}
 	g.CellSize = CellSize
-	g.BroadcastChannel = make(chan interface{}, 100)
 	g.PlayersConnections = make(map[int]*websocket.Conn)
-	g.Panel = [NumberOfRows][NumberOfColumns]string{}
-	g.RandomStart()
+	g.Panel = NewPanel(g.Rows, g.Columns)
+	g.SetSeed(g.Seed) // Replay the same board and drop sequence
+	g.BuildBoard()
 	g.powerupChosen = make(map[string]int)
 	g.GameState = "lobby"
-	LobbyMsg = false
+	g.LobbyMsg = false
 	g.Mu.Unlock()
-	go g.StartBombWatcher()
-	go g.StartBroadcaster()
 	log.Println("Game reset. Waiting for players to join.")
 }
@@ -0,0 +1,286 @@
+package bomberman
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The admin API lets operators inspect and control the rooms. Every request must carry
+// the server's admin token in the AdminTokenHeader header; without a token the API is off.
+
+// AdminHandler wraps an admin endpoint with the token check and the JSON error responses.
+func (m *RoomManager) AdminHandler(method string, handler func(w http.ResponseWriter, r *http.Request) (interface{}, int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusOK
+		var body interface{}
+		var err error
+		switch {
+		case m.AdminToken == "":
+			status, err = http.StatusNotFound, errors.New("admin API is disabled")
+		case subtle.ConstantTimeCompare([]byte(r.Header.Get(AdminTokenHeader)), []byte(m.AdminToken)) != 1:
+			status, err = http.StatusUnauthorized, errors.New("invalid admin token")
+		case r.Method != method:
+			w.Header().Set("Allow", method)
+			status, err = http.StatusMethodNotAllowed, errors.New("method must be "+method)
+		default:
+			body, status, err = handler(w, r)
+		}
+		if err != nil {
+			log.Printf("Admin %s %s: %v\n", r.Method, r.URL.Path, err)
+			body = map[string]string{"error": err.Error()}
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+// adminRoom returns the room given by the 'room' query parameter.
+func (m *RoomManager) adminRoom(r *http.Request) (*GameBoard, int, error) {
+	id := r.URL.Query().Get("room")
+	if id == "" {
+		return nil, http.StatusBadRequest, errors.New("room parameter is required")
+	}
+	g := m.GetRoom(id)
+	if g == nil {
+		return nil, http.StatusNotFound, errors.New("room not found")
+	}
+	return g, http.StatusOK, nil
+}
+
+// AdminBoard returns the full state of a room's board.
+func (m *RoomManager) AdminBoard(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return json.RawMessage(data), http.StatusOK, nil
+}
+
+// AdminPlayers lists the players of a room, or of every room without a 'room' parameter.
+func (m *RoomManager) AdminPlayers(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	var rooms []*GameBoard
+	if r.URL.Query().Get("room") != "" {
+		g, status, err := m.adminRoom(r)
+		if err != nil {
+			return nil, status, err
+		}
+		rooms = append(rooms, g)
+	} else {
+		m.Mu.Lock()
+		for _, g := range m.Rooms {
+			rooms = append(rooms, g)
+		}
+		m.Mu.Unlock()
+	}
+
+	players := []AdminPlayerInfo{}
+	for _, g := range rooms {
+		g.Mu.Lock()
+		for i, player := range g.Players {
+			_, connected := g.PlayersConnections[i]
+			players = append(players, AdminPlayerInfo{
+				Room:      g.ID,
+				Index:     i,
+				Name:      player.Name,
+				UUID:      player.UUID,
+				Connected: connected,
+				IsBot:     player.IsBot,
+				IsDead:    player.IsDead,
+				Lives:     player.Lives,
+				Team:      player.Team,
+			})
+		}
+		g.Mu.Unlock()
+	}
+	return players, http.StatusOK, nil
+}
+
+// AdminKick removes the player given by the 'player' parameter, an index or a UUID, from a room.
+func (m *RoomManager) AdminKick(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	if err := g.KickPlayer(r.URL.Query().Get("player")); err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	return map[string]string{"status": "kicked"}, http.StatusOK, nil
+}
+
+// AdminStart starts a room's match without waiting for the lobby countdown.
+func (m *RoomManager) AdminStart(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	g.Mu.Lock()
+	inLobby := g.GameState == "lobby" && !g.IsStarted
+	players := len(g.Players)
+	gen := g.countdownGen
+	g.Mu.Unlock()
+	if !inLobby {
+		return nil, http.StatusConflict, errors.New("the match has already started")
+	}
+	if players == 0 {
+		return nil, http.StatusConflict, errors.New("the room has no players")
+	}
+	log.Printf("Admin: starting room %s\n", g.ID)
+	go g.forceStartGame(gen)
+	return map[string]string{"status": "starting"}, http.StatusOK, nil
+}
+
+// AdminReset ends a room's match and sends every player away, leaving an empty lobby.
+func (m *RoomManager) AdminReset(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	log.Printf("Admin: resetting room %s\n", g.ID)
+	g.ResetGame()
+	return map[string]string{"status": "reset"}, http.StatusOK, nil
+}
+
+// AdminBroadcast sends a system chat message to a room, or to every room without a
+// 'room' parameter. The body is {"content":"..."}.
+func (m *RoomManager) AdminBroadcast(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	var body struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Content) == "" {
+		return nil, http.StatusBadRequest, errors.New(`body must be {"content":"..."} with a non-empty content`)
+	}
+
+	var rooms []*GameBoard
+	if r.URL.Query().Get("room") != "" {
+		g, status, err := m.adminRoom(r)
+		if err != nil {
+			return nil, status, err
+		}
+		rooms = append(rooms, g)
+	} else {
+		m.Mu.Lock()
+		for _, g := range m.Rooms {
+			rooms = append(rooms, g)
+		}
+		m.Mu.Unlock()
+	}
+	for _, g := range rooms {
+		g.Mu.Lock()
+		g.broadcastSystemChat(body.Content)
+		g.Mu.Unlock()
+	}
+	return map[string]int{"rooms": len(rooms)}, http.StatusOK, nil
+}
+
+// AdminMute mutes the player given by the 'name' parameter in one room, or on the whole
+// server without a 'room' parameter. An optional 'duration' (e.g. 10m) limits the mute.
+func (m *RoomManager) AdminMute(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.New("name parameter is required")
+	}
+	var d time.Duration
+	if value := r.URL.Query().Get("duration"); value != "" {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil || d <= 0 {
+			return nil, http.StatusBadRequest, errors.New("duration must be a positive duration such as 10m")
+		}
+	}
+	if r.URL.Query().Get("room") == "" {
+		m.Moderator.Mute(name, d)
+		return map[string]string{"status": "muted"}, http.StatusOK, nil
+	}
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	g.Mu.Lock()
+	g.MutePlayer(name, d)
+	g.Mu.Unlock()
+	return map[string]string{"status": "muted"}, http.StatusOK, nil
+}
+
+// AdminUnmute lifts the mute of the player given by the 'name' parameter in one room,
+// or on the whole server without a 'room' parameter.
+func (m *RoomManager) AdminUnmute(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.New("name parameter is required")
+	}
+	if r.URL.Query().Get("room") == "" {
+		m.Moderator.Unmute(name)
+		return map[string]string{"status": "unmuted"}, http.StatusOK, nil
+	}
+	g, status, err := m.adminRoom(r)
+	if err != nil {
+		return nil, status, err
+	}
+	g.Mu.Lock()
+	g.UnmutePlayer(name)
+	g.Mu.Unlock()
+	return map[string]string{"status": "unmuted"}, http.StatusOK, nil
+}
+
+// KickPlayer removes a player, given by index or UUID, and closes their connection.
+// In the lobby their slot is freed and the later players move down one slot, each told
+// their new index; in a match they are eliminated and cannot reconnect.
+func (g *GameBoard) KickPlayer(player string) error {
+	g.Mu.Lock()
+	playerIndex := g.GetPlayerByUUID(player)
+	if index, err := strconv.Atoi(player); err == nil {
+		playerIndex = index
+	}
+	if player == "" || !g.validPlayer(playerIndex) {
+		g.Mu.Unlock()
+		return errors.New("player not found")
+	}
+
+	conn := g.PlayersConnections[playerIndex]
+	delete(g.PlayersConnections, playerIndex)
+	name := g.Players[playerIndex].Name
+	inLobby := g.GameState == "lobby"
+	var moved []int
+	if inLobby {
+		moved = g.removeLobbyPlayer(playerIndex)
+		g.StopCountdown = g.countingDown
+	} else {
+		g.Players[playerIndex].UUID = "" // No way back in with the old UUID
+		if !g.Players[playerIndex].IsDead {
+			g.PlayerDeath(playerIndex, -1, CauseDisconnect)
+		}
+	}
+	playerListMsg := g.PlayerListMsg()
+	g.Mu.Unlock()
+	log.Printf("Admin: kicked player %d (%s) from room %s\n", playerIndex, name, g.ID)
+
+	if conn != nil {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Kicked by an admin"),
+			time.Now().Add(time.Second))
+		conn.Close()
+	}
+	if inLobby {
+		for _, index := range moved {
+			g.SendPlayerAccepted(index)
+		}
+		g.SendMsgToChannel(playerListMsg, -1)
+	} else {
+		g.sendPlayerDisconnected(playerIndex)
+	}
+	return nil
+}
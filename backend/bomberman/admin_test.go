@@ -0,0 +1,203 @@
+package bomberman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func adminPost(m *RoomManager, handler http.HandlerFunc, query string) int {
+	r := httptest.NewRequest("POST", "/admin?"+query, nil)
+	r.Header.Set(AdminTokenHeader, m.AdminToken)
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w.Code
+}
+
+func TestAdminMuteAndUnmute(t *testing.T) {
+	m := NewRoomManager()
+	m.AdminToken = "secret"
+	g, err := m.CreateRoom(RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.RemoveRoom(g.ID)
+	mute := m.AdminHandler("POST", m.AdminMute)
+	unmute := m.AdminHandler("POST", m.AdminUnmute)
+
+	for _, query := range []string{"", "name=bob&duration=soon", "name=bob&duration=-1m", "name=bob&room=nope"} {
+		if code := adminPost(m, mute, query); code == http.StatusOK {
+			t.Errorf("mute %q: status 200, want an error", query)
+		}
+	}
+
+	if code := adminPost(m, mute, "name=Bob"); code != http.StatusOK {
+		t.Fatalf("server mute: status %d", code)
+	}
+	if !m.Moderator.IsMuted("bob") {
+		t.Fatal("bob is not muted on the server")
+	}
+	if code := adminPost(m, unmute, "name=bob"); code != http.StatusOK {
+		t.Fatalf("server unmute: status %d", code)
+	}
+	if m.Moderator.IsMuted("bob") {
+		t.Fatal("bob is still muted on the server")
+	}
+
+	if code := adminPost(m, mute, "name=bob&duration=10m&room="+g.ID); code != http.StatusOK {
+		t.Fatalf("room mute: status %d", code)
+	}
+	g.Mu.Lock()
+	_, muted := g.chatMutes["bob"]
+	g.Mu.Unlock()
+	if !muted || m.Moderator.IsMuted("bob") {
+		t.Fatal("bob should be muted in the room only")
+	}
+	if code := adminPost(m, unmute, "name=bob&room="+g.ID); code != http.StatusOK {
+		t.Fatalf("room unmute: status %d", code)
+	}
+	g.Mu.Lock()
+	_, muted = g.chatMutes["bob"]
+	g.Mu.Unlock()
+	if muted {
+		t.Fatal("bob is still muted in the room")
+	}
+}
+
+func TestResetCancelsCountdowns(t *testing.T) {
+	for _, start := range []string{"lobby countdown", "admin start"} {
+		m := NewRoomManager()
+		m.AdminToken = "secret"
+		g, err := m.CreateRoom(RoomOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Mu.Lock()
+		g.Rules.LobbyCountdown = 1
+		g.Rules.GameCountdown = 1
+		for _, name := range []string{"a", "b"} {
+			g.Players = append(g.Players, Player{Index: len(g.Players), Name: name, Lives: g.Rules.Lives})
+		}
+		g.NumberOfPlayers = len(g.Players)
+		g.Mu.Unlock()
+
+		if start == "admin start" {
+			if code := adminPost(m, m.AdminHandler("POST", m.AdminStart), "room="+g.ID); code != http.StatusOK {
+				t.Fatalf("%s: status %d", start, code)
+			}
+		} else {
+			g.Mu.Lock()
+			g.countingDown = true
+			gen := g.countdownGen
+			g.Mu.Unlock()
+			go g.startCountdown(gen)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if code := adminPost(m, m.AdminHandler("POST", m.AdminReset), "room="+g.ID); code != http.StatusOK {
+			t.Fatalf("%s: reset status %d", start, code)
+		}
+		time.Sleep(2500 * time.Millisecond) // Past both countdowns
+
+		g.Mu.Lock()
+		state, started, counting := g.GameState, g.IsStarted, g.countingDown
+		g.Mu.Unlock()
+		if state != "lobby" || started || counting {
+			t.Errorf("%s: after a reset the room is %s (started %v, counting down %v), want an idle lobby", start, state, started, counting)
+		}
+		m.RemoveRoom(g.ID)
+	}
+}
+
+func TestKickMovesLaterLobbyPlayersDown(t *testing.T) {
+	m := NewRoomManager()
+	g, err := m.CreateRoom(RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.RemoveRoom(g.ID)
+	server := httptest.NewServer(http.HandlerFunc(g.HandleWSConnections))
+	defer server.Close()
+
+	var conns []*websocket.Conn
+	for _, name := range []string{"alice", "bob", "carol"} {
+		g.Mu.Lock()
+		uuid, err := g.CreatePlayer(name)
+		g.Mu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?UUID="+uuid, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	waitFor(t, g, "three connections", func() bool { return len(g.PlayersConnections) == 3 })
+
+	if err := g.KickPlayer("0"); err != nil {
+		t.Fatal(err)
+	}
+	g.Mu.Lock()
+	for i, player := range g.Players {
+		if player.Index != i || player.Color != Colors[i] || g.PlayersConnections[i] == nil {
+			t.Errorf("player %s in slot %d has index %d, color %s", player.Name, i, player.Index, player.Color)
+		}
+	}
+	if g.Players[1].Name != "carol" || len(g.PlayersConnections) != 2 {
+		t.Fatalf("slot 1 is %s with %d connections, want carol with 2", g.Players[1].Name, len(g.PlayersConnections))
+	}
+	g.Mu.Unlock()
+
+	// carol is told her new slot
+	for {
+		conns[2].SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg map[string]interface{}
+		if err := conns[2].ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for PlayerAccepted: %v", err)
+		}
+		if msg["type"] == "PlayerAccepted" && msg["index"] == float64(1) {
+			break
+		}
+	}
+
+	// The next player takes a free color and spawn
+	g.Mu.Lock()
+	if _, err := g.CreatePlayer("dave"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Players[2].Color == g.Players[1].Color || g.Players[2].InitialRow == g.Players[1].InitialRow && g.Players[2].InitialColumn == g.Players[1].InitialColumn {
+		t.Errorf("dave got the color or spawn of carol")
+	}
+	g.IsStarted = true
+	g.GameState = "gameStarted"
+	g.Mu.Unlock()
+
+	if err := conns[2].WriteJSON(map[string]string{"msgType": "MS", "d": "r"}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, g, "carol moving", func() bool { return g.Players[1].IsMoving })
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	if g.Players[0].IsMoving || g.Players[2].IsMoving {
+		t.Errorf("carol's input moved another player")
+	}
+}
+
+// waitFor polls cond with g.Mu held until it holds or a second passed.
+func waitFor(t *testing.T, g *GameBoard, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		g.Mu.Lock()
+		ok := cond()
+		g.Mu.Unlock()
+		if ok {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
@@ -6,16 +6,16 @@ import (
 	"time"
 )
 
+func (g *GameBoard) HandleBombMessage(playerIndex int) {
+	g.queueInput(PlayerInput{PlayerIndex: playerIndex, Type: "b"})
+}
 
+func (g *GameBoard) HandleDetonateMessage(playerIndex int) {
+	g.queueInput(PlayerInput{PlayerIndex: playerIndex, Type: "detonate"})
+}
 
-func (g *GameBoard) HandleBombMessage(msgMap map[string]interface{}) {
-	playerIndex, ok := msgMap["fromPlayer"].(int)
-	if !ok {
-		log.Println("fromPlayer not found in message")
-		return
-	}
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
+// applyBomb places a bomb for the player. Must be called with g.Mu held.
+func (g *GameBoard) applyBomb(playerIndex int) {
 	bombIndex, err := g.CreateBomb(playerIndex)
 	if err != nil || bombIndex == -1 {
 		log.Println("Error creating bomb:", err)
@@ -24,26 +24,28 @@ func (g *GameBoard) HandleBombMessage(msgMap map[string]interface{}) {
 	log.Println("Bomb created by player", playerIndex, "at", g.Bombs[bombIndex].Row, g.Bombs[bombIndex].Column)
 	var msg PlantBomb
 	msg.MsgType = "BA" //Bomb Accepted
+	msg.ID = g.Bombs[bombIndex].ID
+	msg.Remote = g.Bombs[bombIndex].Remote
 	msg.Column = g.Bombs[bombIndex].Column
 	msg.Row = g.Bombs[bombIndex].Row
 	msg.XLocation = g.Bombs[bombIndex].XLocation
 	msg.YLocation = g.Bombs[bombIndex].YLocation
 	msg.PlayerIndex = playerIndex
-	g.SendMsgToChannel(msg, playerIndex)
+	g.emit(msg)
 }
 
 func (g *GameBoard) RespawnPlayer(playerIndex int) {
 	g.Players[playerIndex].Row = g.Players[playerIndex].InitialRow
 	g.Players[playerIndex].Column = g.Players[playerIndex].InitialColumn
+	if g.Panel[g.Players[playerIndex].Row][g.Players[playerIndex].Column] == "W" {
+		// Sudden death buried the spawn, come back on the nearest free cell instead
+		g.Players[playerIndex].Row, g.Players[playerIndex].Column = g.nearestOpenCell(g.Players[playerIndex].Row, g.Players[playerIndex].Column)
+	}
 	g.Players[playerIndex].XLocation = g.Players[playerIndex].Column * g.CellSize
 	g.Players[playerIndex].YLocation = g.Players[playerIndex].Row * g.CellSize
 	g.Players[playerIndex].JustRespawned = true
-	g.Players[playerIndex].LastDamageTime = time.Now() // Reset damage time on respawn
-	time.AfterFunc(PlayerInvulnerabilityDuration, func() {
-		g.Mu.Lock()
-		g.Players[playerIndex].JustRespawned = false
-		g.Mu.Unlock()
-	})
+	g.Players[playerIndex].LastDamageTime = g.now() // Reset damage time on respawn
+	g.Players[playerIndex].InvulnerableUntil = g.now().Add(g.Rules.Invulnerability)
 }
 
 // PlayerHitByExplosion checks if a player is currently within any of the given explosion positions.
@@ -65,36 +67,39 @@ func (g *GameBoard) PlayerHitByExplosion(playerIndex int, affectedPositions []Po
 	return false
 }
 
-// DamagePlayer handles the logic for a player taking damage.
-func (g *GameBoard) DamagePlayer(playerIndex int) {
+// DamagePlayer handles the logic for a player taking damage. The attacker is the
+// owner of the bomb or fire, -1 when there is none. The cause is one of the
+// DamageCause constants and is counted in the match stats.
+func (g *GameBoard) DamagePlayer(playerIndex, attackerIndex int, cause string) {
 	player := &g.Players[playerIndex]
 	if player.IsDead || player.JustRespawned {
 		return
 	}
+	if g.isTeamMode() && !g.FriendlyFire && attackerIndex >= 0 && attackerIndex != playerIndex &&
+		!g.areOpponents(playerIndex, attackerIndex) {
+		return
+	}
 
 	// NEW: Check if player was recently damaged to prevent spamming
-	if time.Since(player.LastDamageTime) < BombExplosionDuration/2 { // Small cooldown
+	if g.now().Sub(player.LastDamageTime) < BombExplosionDuration/2 { // Small cooldown
 		return
 	}
 
 	player.Lives--
+	g.Stats.LivesLost[cause]++
 	log.Printf("Player %d hit! Lives remaining: %d\n", playerIndex, player.Lives)
-	player.LastDamageTime = time.Now() // Update last damage time
+	player.LastDamageTime = g.now() // Update last damage time
 
-	if player.IsMoving {
-		player.IsMoving = false
-		if player.StopMoveChan != nil {
-			close(player.StopMoveChan)
-			player.StopMoveChan = nil
-		}
-	}
+	player.IsMoving = false
 
 	if player.Lives <= 0 {
-		g.PlayerDeath(playerIndex)
+		g.PlayerDeath(playerIndex, attackerIndex, cause)
 	} else {
+		g.recordHit(playerIndex, attackerIndex, cause, false)
+		g.loseRemote(playerIndex) // Every death costs the powerup, not only the last one
 		g.PendingRespawns = append(g.PendingRespawns, PlayerRespawn{
 			PlayerIndex: playerIndex,
-			RespawnTime: time.Now().Add(BombExplosionDuration),
+			RespawnTime: g.now().Add(BombExplosionDuration),
 		})
 
 		msg := PlayerExplosionMsg{
@@ -104,15 +109,13 @@ func (g *GameBoard) DamagePlayer(playerIndex int) {
 			PlayerIndex: playerIndex,
 		}
 		g.Players[playerIndex].IsHurt = true
-		g.SendMsgToChannel(msg, -1)
+		g.emit(msg)
 	}
 }
 
-// PeriodicPlayerDamageCheck (NEW FUNCTION)
-// This function will be called repeatedly by the bomb watcher.
+// PeriodicPlayerDamageCheck damages players standing in fire.
+// It is called on every tick of the game loop, with g.Mu held.
 func (g *GameBoard) PeriodicPlayerDamageCheck() {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
 	// Loop through all players
 	for i := range g.Players {
 		player := &g.Players[i]
@@ -125,31 +128,41 @@ func (g *GameBoard) PeriodicPlayerDamageCheck() {
 		playerCellRow := (player.YLocation + PlayerSize/2) / int(g.CellSize)
 		playerCellCol := (player.XLocation + PlayerSize/2) / int(g.CellSize)
 
-		if playerCellRow >= 0 && playerCellRow < NumberOfRows &&
-			playerCellCol >= 0 && playerCellCol < NumberOfColumns {
+		if playerCellRow >= 0 && playerCellRow < g.Rows &&
+			playerCellCol >= 0 && playerCellCol < g.Columns {
 
 			if g.Panel[playerCellRow][playerCellCol] == "Ex" {
 				// Player is on an 'Ex' cell, now check if they should take damage
 				// Use BombExplosionDuration as a general cooldown. This means a player
 				// will only take damage from a fire cell once per full explosion duration,
 				// even if they run back and forth.
-				if time.Since(player.LastDamageTime) > BombExplosionDuration {
+				if g.now().Sub(player.LastDamageTime) > BombExplosionDuration {
 					log.Printf("Player %d walked into fire at [%d,%d]! Applying damage.", i, playerCellRow, playerCellCol)
-					g.DamagePlayer(i)
+					g.DamagePlayer(i, g.fireOrigin(playerCellRow, playerCellCol), CauseFire)
 				}
 			}
 		}
 	}
 }
 
-func (g *GameBoard) PlayerDeath(playerIndex int) {
+// fireOrigin returns the player credited with the most recent fire on a cell, -1 when the cell is not burning.
+func (g *GameBoard) fireOrigin(row, col int) int {
+	for i := len(g.ExplodedCells) - 1; i >= 0; i-- {
+		if g.ExplodedCells[i].Position.Row == row && g.ExplodedCells[i].Position.Col == col {
+			return g.ExplodedCells[i].Origin
+		}
+	}
+	return -1
+}
+
+// PlayerDeath takes a player out of the match. The killer is -1 when nobody is credited.
+func (g *GameBoard) PlayerDeath(playerIndex, killerIndex int, cause string) {
+	g.Stats.Eliminations[cause]++
+	g.recordHit(playerIndex, killerIndex, cause, true)
+	g.loseRemote(playerIndex)
 	g.NumberOfPlayers--
 	g.Players[playerIndex].IsDead = true
 	g.Players[playerIndex].IsMoving = false
-	if g.Players[playerIndex].StopMoveChan != nil {
-		close(g.Players[playerIndex].StopMoveChan)
-		g.Players[playerIndex].StopMoveChan = nil
-	}
 	g.Players[playerIndex].JustRespawned = false
 	g.Players[playerIndex].LastDamageTime = time.Time{} // Reset last damage time
 	g.Players[playerIndex].Lives = 0
@@ -157,7 +170,7 @@ func (g *GameBoard) PlayerDeath(playerIndex int) {
 		Type:   "PD",
 		Player: g.Players[playerIndex],
 	}
-	g.SendMsgToChannel(msg, -1)
+	g.emit(msg)
 	g.CheckGameEnd()
 }
 
@@ -202,16 +215,34 @@ func (g *GameBoard) CreateBomb(playerIndex int) (int, error) {
 	bomb.Row = g.Players[playerIndex].Row
 	bomb.XLocation = bomb.Column * g.CellSize
 	bomb.YLocation = bomb.Row * g.CellSize
-	bomb.ExplosionTime = time.Now().Add(g.Players[playerIndex].BombDelay)
+	bomb.ExplosionTime = g.now().Add(g.Players[playerIndex].BombDelay)
 	bomb.OwnPlayerIndex = playerIndex
 	bomb.InitialIntersection = true
+	bomb.Remote = g.Players[playerIndex].HasRemote
+	bomb.Origin = playerIndex
+	g.nextBombID++
+	bomb.ID = g.nextBombID
 
 	g.Bombs = append(g.Bombs, bomb)
 	bombIndex := len(g.Bombs) - 1
 	return bombIndex, nil
 }
 
-func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int) []Position {
+// CalculateBombRange returns the cells a bomb's blast reaches and gives every
+// destructible wall it hits a chance to drop a powerup.
+func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int, pierce bool) []Position {
+	affectedPositions := g.BlastCells(bombRow, bombCol, bombRange, pierce)
+	for _, pos := range affectedPositions {
+		if g.Panel[pos.Row][pos.Col] == "D" {
+			g.CreatePowerupWithChance(pos.Row, pos.Col)
+		}
+	}
+	return affectedPositions
+}
+
+// BlastCells returns the cells a bomb at (bombRow, bombCol) would reach, without side effects.
+// The blast stops at walls and, unless it pierces, at the first destructible wall in each direction.
+func (g *GameBoard) BlastCells(bombRow, bombCol, bombRange int, pierce bool) []Position {
 	var affectedPositions []Position
 
 	affectedPositions = append(affectedPositions, Position{Row: bombRow, Col: bombCol})
@@ -221,8 +252,7 @@ func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int) []Positi
 			break
 		}
 		affectedPositions = append(affectedPositions, Position{Row: row, Col: bombCol})
-		if g.Panel[row][bombCol] == "D" {
-			g.CreatePowerupWithChance(row, bombCol)
+		if g.Panel[row][bombCol] == "D" && !pierce {
 			break
 		}
 	}
@@ -232,8 +262,7 @@ func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int) []Positi
 			break
 		}
 		affectedPositions = append(affectedPositions, Position{Row: row, Col: bombCol})
-		if g.Panel[row][bombCol] == "D" {
-			g.CreatePowerupWithChance(row, bombCol)
+		if g.Panel[row][bombCol] == "D" && !pierce {
 			break
 		}
 	}
@@ -243,8 +272,7 @@ func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int) []Positi
 			break
 		}
 		affectedPositions = append(affectedPositions, Position{Row: bombRow, Col: col})
-		if g.Panel[bombRow][col] == "D" {
-			g.CreatePowerupWithChance(bombRow, col)
+		if g.Panel[bombRow][col] == "D" && !pierce {
 			break
 		}
 	}
@@ -254,8 +282,7 @@ func (g *GameBoard) CalculateBombRange(bombRow, bombCol, bombRange int) []Positi
 			break
 		}
 		affectedPositions = append(affectedPositions, Position{Row: bombRow, Col: col})
-		if g.Panel[bombRow][col] == "D" {
-			g.CreatePowerupWithChance(bombRow, col)
+		if g.Panel[bombRow][col] == "D" && !pierce {
 			break
 		}
 	}
@@ -275,8 +302,9 @@ func (g *GameBoard) ApplyExplosion(bomb Bomb) {
 		log.Printf("Player with index %d not found for bomb explosion.", bomb.OwnPlayerIndex)
 		return
 	}
+	metrics.countBomb()
 
-	affectedPositions := g.CalculateBombRange(bomb.Row, bomb.Column, player.BombRange)
+	affectedPositions := g.CalculateBombRange(bomb.Row, bomb.Column, player.BombRange, player.Pierce)
 
 	for i := range g.Bombs {
 		if g.Bombs[i].Row == bomb.Row && g.Bombs[i].Column == bomb.Column {
@@ -285,7 +313,13 @@ func (g *GameBoard) ApplyExplosion(bomb Bomb) {
 
 		for _, pos := range affectedPositions {
 			if g.Bombs[i].Row == pos.Row && g.Bombs[i].Column == pos.Col {
-				g.Bombs[i].ExplosionTime = time.Now()
+				g.Bombs[i].ExplosionTime = g.now()
+				g.Bombs[i].Remote = false
+				if !g.Bombs[i].Chained && g.Bombs[i].OwnPlayerIndex != bomb.Origin {
+					// The chain goes on in the name of whoever started it
+					g.Bombs[i].Origin = bomb.Origin
+					g.Bombs[i].Chained = true
+				}
 				break
 			}
 		}
@@ -301,30 +335,38 @@ func (g *GameBoard) ApplyExplosion(bomb Bomb) {
 		}
 
 		cell := &g.Panel[pos.Row][pos.Col]
+		if *cell == "D" && bomb.Origin >= 0 && bomb.Origin < len(g.Players) {
+			g.Players[bomb.Origin].Score += g.Rules.WallPoints
+		}
 		if *cell != "W" {
 			*cell = "Ex"
-			g.ExplodedCells = append(g.ExplodedCells, ExplodedCellInfo{Position: pos, ClearTime: time.Now().Add(BombExplosionDuration)})
+			g.ExplodedCells = append(g.ExplodedCells, ExplodedCellInfo{Position: pos, ClearTime: g.now().Add(BombExplosionDuration), Origin: bomb.Origin})
 			msg.Positions = append(msg.Positions, Position{Row: pos.Row, Col: pos.Col, CellOnFire: true})
 		}
 	}
-	g.SendMsgToChannel(msg, -1)
+	g.emit(msg)
 
 	// IMMEDIATE DAMAGE: Check and damage players caught in THIS SPECIFIC explosion.
 	for i := range g.Players {
 		if g.PlayerHitByExplosion(i, affectedPositions) {
-			g.DamagePlayer(i)
+			cause := CauseOpponentBomb
+			switch {
+			case i == bomb.Origin:
+				cause = CauseOwnBomb
+			case bomb.Chained:
+				cause = CauseChain
+			}
+			g.DamagePlayer(i, bomb.Origin, cause)
 		}
 	}
 
 	player.NumberOfUsedBombs--
 }
 
+// ClearExpiredExplosions puts out fire cells whose time is up. Must be called with g.Mu held.
 func (g *GameBoard) ClearExpiredExplosions() {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
-
 	var remainingExplodedCells []ExplodedCellInfo
-	now := time.Now()
+	now := g.now()
 	var msg ExploadeCellsMsg
 	for _, info := range g.ExplodedCells {
 		if now.After(info.ClearTime) {
@@ -350,17 +392,22 @@ func (g *GameBoard) ClearExpiredExplosions() {
 		}
 	}
 	if msg.MsgType == "OF" {
-		g.SendMsgToChannel(msg, -1)
+		g.emit(msg)
 	}
 	g.ExplodedCells = remainingExplodedCells
 }
 
+// ProcessRespawns respawns hurt players and ends the invulnerability of respawned ones.
+// Must be called with g.Mu held.
 func (g *GameBoard) ProcessRespawns() {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
-
 	var remainingRespawns []PlayerRespawn
-	now := time.Now()
+	now := g.now()
+
+	for i := range g.Players {
+		if g.Players[i].JustRespawned && now.After(g.Players[i].InvulnerableUntil) {
+			g.Players[i].JustRespawned = false
+		}
+	}
 
 	for _, respawn := range g.PendingRespawns {
 		if now.After(respawn.RespawnTime) {
@@ -378,7 +425,7 @@ func (g *GameBoard) ProcessRespawns() {
 				XLocation:   player.XLocation,
 				YLocation:   player.YLocation,
 			}
-			g.SendMsgToChannel(msg, -1)
+			g.emit(msg)
 		} else {
 			remainingRespawns = append(remainingRespawns, respawn)
 		}
@@ -386,40 +433,165 @@ func (g *GameBoard) ProcessRespawns() {
 	g.PendingRespawns = remainingRespawns
 }
 
-func (g *GameBoard) StartBombWatcher() {
-	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond) // Check every 100ms
-		defer ticker.Stop()
+// checkBombs explodes every bomb whose timer ran out, and the bombs their blasts set off,
+// one at a time. A bomb leaves g.Bombs before it explodes, so the changes an explosion makes
+// to the other bombs (chains, lost remotes) are never overwritten. Must be called with g.Mu held.
+func (g *GameBoard) checkBombs() {
+	for {
+		i := g.dueBomb()
+		if i == -1 {
+			return
+		}
+		bomb := g.Bombs[i]
+		g.Bombs = append(g.Bombs[:i], g.Bombs[i+1:]...)
+		g.ApplyExplosion(bomb)
+	}
+}
 
-		for range ticker.C {
-			//g.Mu.Lock() // Lock for the entire loop iteration
-			g.checkBombs()
-			g.ClearExpiredExplosions()
-			g.ProcessRespawns()
-			g.PeriodicPlayerDamageCheck() // NEW: Call the periodic damage check here
-			//g.Mu.Unlock()
+// dueBomb returns the index of the first bomb that must explode now, or -1. Must be called with g.Mu held.
+func (g *GameBoard) dueBomb() int {
+	now := g.now()
+	for i, bomb := range g.Bombs {
+		if !bomb.Remote && !bomb.ExplosionTime.After(now) {
+			return i
 		}
-	}()
+	}
+	return -1
 }
 
-func (g *GameBoard) checkBombs() {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
-	// This function already acquires its own lock, so no need for g.Mu.Lock() around it in StartBombWatcher
-	// However, if you're calling it from StartBombWatcher within a g.Mu.Lock() block,
-	// it would cause a deadlock. Let's adjust StartBombWatcher to lock around the *whole* tick.
-	// (See StartBombWatcher definition above)
+// kickBomb sends the bomb in front of the player sliding in the given direction.
+// It reports whether a bomb was kicked. Must be called with g.Mu held.
+func (g *GameBoard) kickBomb(playerIndex int, direction string) bool {
+	player := &g.Players[playerIndex]
+	d := directionOffsets[direction]
+	row, col := player.Row+d[0], player.Column+d[1]
+	for i := range g.Bombs {
+		bomb := &g.Bombs[i]
+		if bomb.Row != row || bomb.Column != col || bomb.Sliding != "" {
+			continue
+		}
+		if !g.bombCanEnter(i, row+d[0], col+d[1]) {
+			return false
+		}
+		bomb.Sliding = direction
+		bomb.InitialIntersection = false
+		log.Printf("Player %d kicked the bomb at [%d,%d] %s\n", playerIndex, row, col, direction)
+		return true
+	}
+	return false
+}
+
+// bombCanEnter tells whether a bomb can slide into a cell: inside the board, empty,
+// without another bomb and without a player. Must be called with g.Mu held.
+func (g *GameBoard) bombCanEnter(bombIndex, row, col int) bool {
+	if row < 0 || row >= g.Rows || col < 0 || col >= g.Columns || g.Panel[row][col] != "" {
+		return false
+	}
+	for i, bomb := range g.Bombs {
+		if i != bombIndex && bomb.Row == row && bomb.Column == col {
+			return false
+		}
+	}
+	x, y := col*g.CellSize, row*g.CellSize
+	for _, player := range g.Players {
+		if !player.IsDead &&
+			player.XLocation < x+g.CellSize && player.XLocation+PlayerSize > x &&
+			player.YLocation < y+g.CellSize && player.YLocation+PlayerSize > y {
+			return false
+		}
+	}
+	return true
+}
+
+// advanceSlidingBombs moves every kicked bomb one step. A bomb only starts moving into a
+// cell that is free and stops on the cell before the first obstacle. Must be called with g.Mu held.
+func (g *GameBoard) advanceSlidingBombs() {
+	step := BombSlideStep * referenceTickRate / g.TickRate
+	if step < 1 {
+		step = 1
+	}
+	for i := range g.Bombs {
+		bomb := &g.Bombs[i]
+		if bomb.Sliding == "" {
+			continue
+		}
+		d := directionOffsets[bomb.Sliding]
+		aligned := bomb.XLocation == bomb.Column*g.CellSize && bomb.YLocation == bomb.Row*g.CellSize
+		if aligned && !g.bombCanEnter(i, bomb.Row+d[0], bomb.Column+d[1]) {
+			bomb.Sliding = ""
+			continue
+		}
+
+		if !aligned && g.Panel[bomb.Row+d[0]][bomb.Column+d[1]] != "" {
+			// Something landed in the way mid-slide, fall back onto the bomb's cell
+			bomb.XLocation, bomb.YLocation = bomb.Column*g.CellSize, bomb.Row*g.CellSize
+			bomb.Sliding = ""
+			g.emit(BombMoveMsg{MsgType: "BM", ID: bomb.ID, Row: bomb.Row, Column: bomb.Column, XLocation: bomb.XLocation, YLocation: bomb.YLocation})
+			continue
+		}
+
+		targetX, targetY := (bomb.Column+d[1])*g.CellSize, (bomb.Row+d[0])*g.CellSize
+		bomb.XLocation += d[1] * step
+		bomb.YLocation += d[0] * step
+		if (bomb.XLocation-targetX)*d[1] >= 0 && (bomb.YLocation-targetY)*d[0] >= 0 {
+			bomb.XLocation, bomb.YLocation = targetX, targetY
+			bomb.Row, bomb.Column = bomb.Row+d[0], bomb.Column+d[1]
+		}
+		g.emit(BombMoveMsg{
+			MsgType:   "BM",
+			ID:        bomb.ID,
+			Row:       bomb.Row,
+			Column:    bomb.Column,
+			XLocation: bomb.XLocation,
+			YLocation: bomb.YLocation,
+		})
+	}
+}
 
-	var remainingBombs []Bomb
-	now := time.Now()
+// applyDetonate sets off the player's oldest remote bomb. Like a chain reaction, it
+// explodes on the next pass of checkBombs. Must be called with g.Mu held.
+func (g *GameBoard) applyDetonate(playerIndex int) {
+	for i := range g.Bombs {
+		bomb := &g.Bombs[i]
+		if bomb.OwnPlayerIndex == playerIndex && bomb.Remote {
+			bomb.Remote = false
+			bomb.ExplosionTime = g.now()
+			log.Println("Player", playerIndex, "detonated the bomb at", bomb.Row, bomb.Column)
+			return
+		}
+	}
+}
 
-	for _, bomb := range g.Bombs {
+// loseRemote takes the remote control away from a player. Their remote bombs get a
+// normal timer so they still go off. Must be called with g.Mu held.
+func (g *GameBoard) loseRemote(playerIndex int) {
+	g.Players[playerIndex].HasRemote = false
+	for i := range g.Bombs {
+		if g.Bombs[i].OwnPlayerIndex == playerIndex && g.Bombs[i].Remote {
+			g.Bombs[i].Remote = false
+			g.Bombs[i].ExplosionTime = g.now().Add(g.Players[playerIndex].BombDelay)
+		}
+	}
+}
 
-		if now.After(bomb.ExplosionTime) {
-			g.ApplyExplosion(bomb)
+// recordHit scores a life lost and announces it in the kill feed. Taking a life from an
+// opponent counts as a kill; losing one to your own bomb, or taking a teammate's, as a self-kill.
+func (g *GameBoard) recordHit(victimIndex, killerIndex int, cause string, fatal bool) {
+	g.Players[victimIndex].Deaths++
+	if killerIndex >= 0 && killerIndex < len(g.Players) {
+		killer := &g.Players[killerIndex]
+		if g.areOpponents(killerIndex, victimIndex) {
+			killer.Score += g.Rules.KillPoints
+			killer.Kills++
 		} else {
-			remainingBombs = append(remainingBombs, bomb)
+			killer.Score += g.Rules.SelfKillPoints
 		}
 	}
-	g.Bombs = remainingBombs
+	g.emit(KillFeedMsg{
+		Type:   "KillFeed",
+		Killer: killerIndex,
+		Victim: victimIndex,
+		Cause:  cause,
+		Fatal:  fatal,
+	})
 }
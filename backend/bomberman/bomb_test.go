@@ -0,0 +1,221 @@
+package bomberman
+
+import (
+	"testing"
+	"time"
+)
+
+// newBombTestBoard returns an empty 11x13 board on a fixed clock, with one player on each given cell.
+func newBombTestBoard(cells ...[2]int) *GameBoard {
+	g := InitGame("test", 1)
+	clock := time.Unix(1000, 0)
+	g.Clock = func() time.Time { return clock }
+	g.Panel = NewPanel(g.Rows, g.Columns)
+	for i, cell := range cells {
+		g.Players = append(g.Players, Player{
+			Index:         i,
+			Name:          string(rune('a' + i)),
+			Lives:         g.Rules.Lives,
+			Row:           cell[0],
+			Column:        cell[1],
+			XLocation:     cell[1] * g.CellSize,
+			YLocation:     cell[0] * g.CellSize,
+			BombRange:     2,
+			BombDelay:     g.Rules.BombDelay,
+			NumberOfBombs: 3,
+		})
+	}
+	g.NumberOfPlayers = len(g.Players)
+	g.IsStarted = true
+	g.GameState = "gameStarted"
+	return g
+}
+
+// testBomb is a bomb of a player at a cell, going off after delay (a negative delay means it is due).
+func testBomb(g *GameBoard, owner, row, col int, delay time.Duration) Bomb {
+	g.nextBombID++
+	g.Players[owner].NumberOfUsedBombs++
+	return Bomb{
+		ID:             g.nextBombID,
+		Row:            row,
+		Column:         col,
+		OwnPlayerIndex: owner,
+		Origin:         owner,
+		ExplosionTime:  g.now().Add(delay),
+	}
+}
+
+func TestBlastSetsOffRemoteBomb(t *testing.T) {
+	for _, due := range []bool{true, false} { // the exploding bomb first in g.Bombs, then last
+		g := newBombTestBoard([2]int{9, 1}, [2]int{9, 11})
+		timed := testBomb(g, 0, 1, 3, -time.Second)
+		remote := testBomb(g, 1, 1, 5, time.Hour)
+		remote.Remote = true
+		if due {
+			g.Bombs = []Bomb{timed, remote}
+		} else {
+			g.Bombs = []Bomb{remote, timed}
+		}
+
+		g.checkBombs()
+
+		if len(g.Bombs) != 0 {
+			t.Errorf("timed bomb first=%v: bombs left %+v, want the remote bomb set off too", due, g.Bombs)
+		}
+	}
+}
+
+func TestOwnerDeathSetsOffRemoteBombs(t *testing.T) {
+	for _, ownerFirst := range []bool{true, false} {
+		// Player 1 stands in the blast of player 0's bomb with a single life and a remote bomb far away
+		g := newBombTestBoard([2]int{9, 1}, [2]int{1, 4})
+		g.Players[1].Lives = 1
+		g.Players[1].HasRemote = true
+		timed := testBomb(g, 0, 1, 3, -time.Second)
+		remote := testBomb(g, 1, 9, 11, time.Hour)
+		remote.Remote = true
+		if ownerFirst {
+			g.Bombs = []Bomb{remote, timed}
+		} else {
+			g.Bombs = []Bomb{timed, remote}
+		}
+
+		g.checkBombs()
+
+		if !g.Players[1].IsDead {
+			t.Fatalf("player 1 should have died in the blast")
+		}
+		if len(g.Bombs) != 1 || g.Bombs[0].Remote {
+			t.Errorf("remote bomb first=%v: bombs %+v, want the dead player's bomb back on a timer", ownerFirst, g.Bombs)
+		}
+	}
+}
+
+func TestLostLifeCostsTheRemote(t *testing.T) {
+	// Player 1 has lives to spare, stands in the blast of player 0's bomb and has a remote bomb far away
+	g := newBombTestBoard([2]int{9, 1}, [2]int{1, 4})
+	g.Players[1].HasRemote = true
+	timed := testBomb(g, 0, 1, 3, -time.Second)
+	remote := testBomb(g, 1, 9, 11, time.Hour)
+	remote.Remote = true
+	g.Bombs = []Bomb{timed, remote}
+
+	g.checkBombs()
+
+	if g.Players[1].IsDead || g.Players[1].Lives != g.Rules.Lives-1 {
+		t.Fatalf("player 1 should have lost one life, has %d", g.Players[1].Lives)
+	}
+	if g.Players[1].HasRemote {
+		t.Error("player 1 kept the remote control after losing a life")
+	}
+	if len(g.Bombs) != 1 || g.Bombs[0].Remote || !g.Bombs[0].ExplosionTime.Equal(g.now().Add(g.Players[1].BombDelay)) {
+		t.Errorf("bombs %+v, want player 1's bomb back on a timer", g.Bombs)
+	}
+}
+
+func TestChainKillIsCreditedToChainStarter(t *testing.T) {
+	for _, starterFirst := range []bool{true, false} {
+		// Player 0's bomb sets off player 1's bomb, whose blast hits player 2
+		g := newBombTestBoard([2]int{9, 1}, [2]int{9, 11}, [2]int{1, 5})
+		starter := testBomb(g, 0, 1, 1, -time.Second)
+		chained := testBomb(g, 1, 1, 3, time.Hour)
+		if starterFirst {
+			g.Bombs = []Bomb{starter, chained}
+		} else {
+			g.Bombs = []Bomb{chained, starter}
+		}
+
+		g.checkBombs()
+
+		var feed []KillFeedMsg
+		for _, update := range g.pendingUpdates {
+			if msg, ok := update.(KillFeedMsg); ok {
+				feed = append(feed, msg)
+			}
+		}
+		want := KillFeedMsg{Type: "KillFeed", Killer: 0, Victim: 2, Cause: CauseChain}
+		if len(feed) != 1 || feed[0] != want {
+			t.Errorf("starter first=%v: kill feed %+v, want [%+v]", starterFirst, feed, want)
+		}
+		if g.Players[0].Kills != 1 || g.Players[1].Kills != 0 {
+			t.Errorf("starter first=%v: kills %d and %d, want the kill credited to player 0", starterFirst, g.Players[0].Kills, g.Players[1].Kills)
+		}
+	}
+}
+
+func TestKickedBombSlidesUntilBlocked(t *testing.T) {
+	// Player 0 walks right into their bomb, which slides until the wall at [1,6]
+	g := newBombTestBoard([2]int{1, 1})
+	g.Panel[1][6] = "W"
+	player := &g.Players[0]
+	player.StepSize, player.CanKick = 5, true
+	player.XLocation = 52
+	player.IsMoving, player.DirectionFace = true, "r"
+	bomb := testBomb(g, 0, 1, 2, time.Hour)
+	bomb.XLocation, bomb.YLocation = 2*g.CellSize, g.CellSize
+	g.Bombs = []Bomb{bomb}
+
+	g.advanceMovement()
+	if g.Bombs[0].Sliding != "r" {
+		t.Fatalf("bomb sliding %q after the player walked into it, want r", g.Bombs[0].Sliding)
+	}
+	for tick := 0; tick < 100 && g.Bombs[0].Sliding != ""; tick++ {
+		g.advanceSlidingBombs()
+	}
+	if got := g.Bombs[0]; got.Sliding != "" || got.Row != 1 || got.Column != 5 || got.XLocation != 5*g.CellSize {
+		t.Errorf("bomb %+v, want it stopped on [1,5] before the wall", got)
+	}
+}
+
+func TestKickNeedsThePowerupAndRoom(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		canKick bool
+		blocked bool
+	}{
+		{"without kick", false, false},
+		{"blocked by a wall", true, true},
+	} {
+		g := newBombTestBoard([2]int{1, 1})
+		if tc.blocked {
+			g.Panel[1][3] = "D"
+		}
+		player := &g.Players[0]
+		player.StepSize, player.CanKick = 5, tc.canKick
+		player.XLocation = 52
+		player.IsMoving, player.DirectionFace = true, "r"
+		bomb := testBomb(g, 0, 1, 2, time.Hour)
+		bomb.XLocation, bomb.YLocation = 2*g.CellSize, g.CellSize
+		g.Bombs = []Bomb{bomb}
+
+		g.advanceMovement()
+
+		if g.Bombs[0].Sliding != "" {
+			t.Errorf("%s: the bomb was kicked", tc.name)
+		}
+		if player.IsMoving {
+			t.Errorf("%s: the player kept moving into the bomb", tc.name)
+		}
+	}
+}
+
+func TestPierceBlastGoesThroughDestructibles(t *testing.T) {
+	g := newBombTestBoard()
+	g.Panel[1][2], g.Panel[1][3], g.Panel[1][5] = "D", "D", "W"
+	blastRight := func(pierce bool) []Position {
+		var right []Position
+		for _, pos := range g.BlastCells(1, 1, 5, pierce) {
+			if pos.Row == 1 && pos.Col > 1 {
+				right = append(right, pos)
+			}
+		}
+		return right
+	}
+
+	if got := blastRight(false); len(got) != 1 || got[0].Col != 2 {
+		t.Errorf("blast without pierce reaches %+v, want only the first destructible [1,2]", got)
+	}
+	if got := blastRight(true); len(got) != 3 || got[2].Col != 4 {
+		t.Errorf("piercing blast reaches %+v, want [1,2] to [1,4], stopping at the wall", got)
+	}
+}
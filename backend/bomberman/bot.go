@@ -0,0 +1,397 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// Bots are server-side players. They have no connection: every tick they look at the
+// board and queue the same MS/ME/b inputs a client would send.
+
+// AddBot adds a bot player to the lobby. Must be called with g.Mu held.
+func (g *GameBoard) AddBot(level string) error {
+	settings, ok := BotLevels[level]
+	if !ok {
+		return fmt.Errorf("unknown bot level %q", level)
+	}
+	name := ""
+	for i := 1; ; i++ {
+		name = fmt.Sprintf("Bot %d", i)
+		if !g.IsPlayerNameTaken(name) {
+			break
+		}
+	}
+	UUID, err := g.CreatePlayer(name)
+	if err != nil {
+		return err
+	}
+	index := g.GetPlayerByUUID(UUID)
+	g.Players[index].IsBot = true
+	g.bots = append(g.bots, &Bot{UUID: UUID, Level: level, settings: settings})
+	log.Printf("Room %s: added %s bot %s as player %d\n", g.ID, level, name, index)
+	return nil
+}
+
+// FillWithBots adds bots until the room is full. Must be called with g.Mu held.
+func (g *GameBoard) FillWithBots(level string) error {
+	for g.CanCreateNewPlayer() {
+		if err := g.AddBot(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NumberOfBots returns how many players are bots. Must be called with g.Mu held.
+func (g *GameBoard) NumberOfBots() int {
+	count := 0
+	for _, player := range g.Players {
+		if player.IsBot {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *GameBoard) IsPlayerNameTaken(name string) bool {
+	for _, p := range g.Players {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runBots lets every living bot act. Runs at the start of a tick, before the inputs
+// are processed. Must be called with g.Mu held.
+func (g *GameBoard) runBots() {
+	if g.GameState != "gameStarted" || len(g.bots) == 0 {
+		return
+	}
+	danger := g.dangerCells()
+	for _, bot := range g.bots {
+		index := g.GetPlayerByUUID(bot.UUID)
+		if index == -1 {
+			continue
+		}
+		player := &g.Players[index]
+		if player.IsDead || player.IsHurt {
+			bot.path = nil
+			continue
+		}
+
+		cell := [2]int{player.Row, player.Column}
+		escaping := len(bot.path) > 0 && !danger[bot.path[len(bot.path)-1]]
+		switch {
+		case g.TickCount >= bot.nextDecision,
+			len(bot.path) == 0,
+			!bot.settings.slowReaction && danger[cell] && !escaping,
+			!g.botCanEnter(bot.path[0]),
+			bot.stuck > botStuckTicks:
+			if bot.stuck > botStuckTicks {
+				bot.stuck = 0
+			}
+			bot.nextDecision = g.TickCount + bot.settings.thinkInterval
+			g.decideBot(bot, index, danger)
+		}
+		g.steerBot(bot, index)
+		if player.HasRemote && g.botSafeFromRemote(index) {
+			g.botInput(index, PlayerInput{PlayerIndex: index, Type: "detonate"})
+		}
+	}
+}
+
+// botSafeFromRemote tells whether the bot's oldest remote bomb can go off without
+// hurting the bot or a teammate.
+func (g *GameBoard) botSafeFromRemote(index int) bool {
+	for _, bomb := range g.Bombs {
+		if bomb.OwnPlayerIndex != index || !bomb.Remote {
+			continue
+		}
+		for _, pos := range g.BlastCells(bomb.Row, bomb.Column, g.bombRange(bomb), g.bombPierces(bomb)) {
+			for i, other := range g.Players {
+				if !other.IsDead && !g.areOpponents(index, i) && other.Row == pos.Row && other.Column == pos.Col {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// decideBot picks the bot's next goal: run from danger, drop a bomb, or walk
+// towards a powerup, a destructible wall or an opponent.
+func (g *GameBoard) decideBot(bot *Bot, index int, danger map[[2]int]bool) {
+	player := &g.Players[index]
+	cell := [2]int{player.Row, player.Column}
+	bot.path = nil
+
+	if danger[cell] {
+		bot.path = g.botPath(cell, g.botCanEnter, func(c [2]int) bool { return !danger[c] })
+		return
+	}
+
+	if g.botWantsBomb(bot, index) && g.CanCreateBomb(index) {
+		withBomb := make(map[[2]int]bool, len(danger))
+		for c := range danger {
+			withBomb[c] = true
+		}
+		for _, pos := range g.BlastCells(cell[0], cell[1], player.BombRange, player.Pierce) {
+			withBomb[[2]int{pos.Row, pos.Col}] = true
+		}
+		escape := g.botPath(cell, g.botCanEnter, func(c [2]int) bool { return !withBomb[c] })
+		if escape != nil && g.botCanMakeIt(player, len(escape)) {
+			g.botInput(index, PlayerInput{PlayerIndex: index, Type: "b"})
+			bot.path = escape
+			return
+		}
+	}
+
+	if g.rng.Float64() < bot.settings.wander {
+		var options [][2]int
+		for _, dir := range []string{"u", "d", "l", "r"} {
+			d := directionOffsets[dir]
+			next := [2]int{cell[0] + d[0], cell[1] + d[1]}
+			if g.botCanEnter(next) && !danger[next] {
+				options = append(options, next)
+			}
+		}
+		if len(options) > 0 {
+			bot.path = [][2]int{options[g.rng.Intn(len(options))]}
+		}
+		return
+	}
+
+	// The current cell is not a goal: the bot would have bombed it if it could
+	safe := func(c [2]int) bool { return g.botCanEnter(c) && !danger[c] }
+	bot.path = g.botPath(cell, safe, func(c [2]int) bool { return c != cell && g.botIsGoal(bot, index, c) })
+	if len(bot.path) == 0 && bot.settings.hunt {
+		// Nothing to break or pick up nearby: close in on the nearest opponent
+		bot.path = g.botPath(cell, safe, func(c [2]int) bool { return g.botNextToOpponent(index, c) })
+	}
+}
+
+// botIsGoal tells whether a cell is worth walking to.
+func (g *GameBoard) botIsGoal(bot *Bot, index int, c [2]int) bool {
+	if bot.settings.collect {
+		if i := g.FindPowerupAt(c[0], c[1]); i != -1 && !g.Powerups[i].IsHidden {
+			return true
+		}
+	}
+	for _, d := range directionOffsets {
+		row, col := c[0]+d[0], c[1]+d[1]
+		if row >= 0 && row < g.Rows && col >= 0 && col < g.Columns && g.Panel[row][col] == "D" {
+			return true
+		}
+	}
+	return bot.settings.hunt && g.botOpponentInBlast(index, c)
+}
+
+// botWantsBomb tells whether a bomb on the bot's cell would break a wall or hit an opponent.
+func (g *GameBoard) botWantsBomb(bot *Bot, index int) bool {
+	player := &g.Players[index]
+	if g.botOpponentInBlast(index, [2]int{player.Row, player.Column}) {
+		return true
+	}
+	for _, pos := range g.BlastCells(player.Row, player.Column, player.BombRange, player.Pierce) {
+		if g.Panel[pos.Row][pos.Col] == "D" {
+			return true
+		}
+	}
+	return false
+}
+
+// botOpponentInBlast tells whether a bomb dropped on c by the player would reach an opponent.
+func (g *GameBoard) botOpponentInBlast(index int, c [2]int) bool {
+	for _, pos := range g.BlastCells(c[0], c[1], g.Players[index].BombRange, g.Players[index].Pierce) {
+		for i, other := range g.Players {
+			if g.areOpponents(index, i) && !other.IsDead && other.Row == pos.Row && other.Column == pos.Col {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *GameBoard) botNextToOpponent(index int, c [2]int) bool {
+	for i, other := range g.Players {
+		if !g.areOpponents(index, i) || other.IsDead {
+			continue
+		}
+		if abs(other.Row-c[0])+abs(other.Column-c[1]) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// botCanMakeIt tells whether the player can walk the given number of cells before a bomb explodes.
+func (g *GameBoard) botCanMakeIt(player *Player, cells int) bool {
+	ticksPerCell := g.CellSize/g.stepSize(player) + 1
+	fuseTicks := int(player.BombDelay.Seconds() * float64(g.TickRate))
+	return cells*ticksPerCell < fuseTicks
+}
+
+// dangerCells returns every cell that is on fire, in the blast of a ticking bomb or about to be
+// buried by sudden death.
+func (g *GameBoard) dangerCells() map[[2]int]bool {
+	danger := make(map[[2]int]bool)
+	for row := range g.Panel {
+		for col := range g.Panel[row] {
+			if g.Panel[row][col] == "Ex" {
+				danger[[2]int{row, col}] = true
+			}
+		}
+	}
+	for _, bomb := range g.Bombs {
+		for _, pos := range g.BlastCells(bomb.Row, bomb.Column, g.bombRange(bomb), g.bombPierces(bomb)) {
+			danger[[2]int{pos.Row, pos.Col}] = true
+		}
+	}
+	for _, c := range g.collapsingCells(botCollapseWarning) {
+		danger[c] = true
+	}
+	return danger
+}
+
+// bombRange returns the range of a bomb, which is its owner's current bomb range.
+func (g *GameBoard) bombRange(bomb Bomb) int {
+	if bomb.OwnPlayerIndex >= 0 && bomb.OwnPlayerIndex < len(g.Players) {
+		return g.Players[bomb.OwnPlayerIndex].BombRange
+	}
+	return g.Rules.BombRange
+}
+
+// bombPierces tells whether a bomb's blast goes through destructible walls, which depends on its owner.
+func (g *GameBoard) bombPierces(bomb Bomb) bool {
+	return bomb.OwnPlayerIndex >= 0 && bomb.OwnPlayerIndex < len(g.Players) && g.Players[bomb.OwnPlayerIndex].Pierce
+}
+
+// botCanEnter tells whether a bot may walk into a cell: inside the board, empty and without a bomb.
+func (g *GameBoard) botCanEnter(c [2]int) bool {
+	if c[0] < 0 || c[0] >= g.Rows || c[1] < 0 || c[1] >= g.Columns || g.Panel[c[0]][c[1]] != "" {
+		return false
+	}
+	for _, bomb := range g.Bombs {
+		if bomb.Row == c[0] && bomb.Column == c[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// botPath finds the shortest path from start to the nearest goal cell, walking only
+// through cells allowed by canEnter. The path leaves out start. It is empty when start
+// is a goal and nil when no goal can be reached.
+func (g *GameBoard) botPath(start [2]int, canEnter, isGoal func([2]int) bool) [][2]int {
+	if isGoal(start) {
+		return [][2]int{}
+	}
+	previous := map[[2]int][2]int{start: start}
+	queue := [][2]int{start}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range []string{"u", "d", "l", "r"} {
+			d := directionOffsets[dir]
+			next := [2]int{cell[0] + d[0], cell[1] + d[1]}
+			if _, seen := previous[next]; seen || !canEnter(next) {
+				continue
+			}
+			previous[next] = cell
+			if isGoal(next) {
+				var path [][2]int
+				for c := next; c != start; c = previous[c] {
+					path = append([][2]int{c}, path...)
+				}
+				return path
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// steerBot moves the bot towards the next cell of its path, one input at a time.
+func (g *GameBoard) steerBot(bot *Bot, index int) {
+	player := &g.Players[index]
+	step := g.stepSize(player)
+
+	for len(bot.path) > 0 {
+		next := bot.path[0]
+		dx := next[1]*g.CellSize - player.XLocation
+		dy := next[0]*g.CellSize - player.YLocation
+		if abs(dx) < step && abs(dy) < step {
+			bot.path = bot.path[1:]
+			continue
+		}
+
+		// Move along the longer axis, but straighten up on the other one first when the
+		// offset is too large for the movement code to snap around corners
+		horizontal := abs(dx) >= abs(dy)
+		if horizontal && abs(dy) > movementTolerance {
+			horizontal = false
+		} else if !horizontal && abs(dx) > movementTolerance {
+			horizontal = true
+		}
+		if bot.stuck > botStuckTicks/2 && abs(dx) >= step && abs(dy) >= step {
+			horizontal = !horizontal
+		}
+		direction := "u"
+		switch {
+		case horizontal && dx > 0:
+			direction = "r"
+		case horizontal:
+			direction = "l"
+		case dy > 0:
+			direction = "d"
+		}
+
+		if player.XLocation == bot.lastX && player.YLocation == bot.lastY {
+			bot.stuck++
+		} else {
+			bot.stuck = 0
+		}
+		bot.lastX, bot.lastY = player.XLocation, player.YLocation
+
+		if !player.IsMoving || player.DirectionFace != direction {
+			g.botInput(index, PlayerInput{PlayerIndex: index, Type: "MS", Direction: direction})
+		}
+		return
+	}
+
+	if player.IsMoving {
+		g.botInput(index, PlayerInput{PlayerIndex: index, Type: "ME"})
+	}
+}
+
+// botInput queues a bot's input and records it like a client message.
+func (g *GameBoard) botInput(index int, input PlayerInput) {
+	g.inputs = append(g.inputs, input)
+	if g.recorder == nil {
+		return
+	}
+	var msg interface{}
+	switch input.Type {
+	case "MS":
+		msg = MoveStartMsg{MsgType: "MS", Direction: input.Direction}
+	case "ME":
+		msg = MoveEndMsg{MsgType: "ME"}
+	case "b":
+		msg = BombMsg{MsgType: "b"}
+	case "detonate":
+		msg = DetonateMsg{MsgType: "detonate"}
+	}
+	if data, err := json.Marshal(msg); err == nil {
+		g.recorder.Record("in", index, data)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package bomberman
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBotPath(t *testing.T) {
+	g := newBombTestBoard([2]int{0, 0})
+	// A wall from (0,2) down to (3,2) forces the way round through row 4
+	for row := 0; row < 4; row++ {
+		g.Panel[row][2] = "W"
+	}
+	goal := func(target [2]int) func([2]int) bool {
+		return func(c [2]int) bool { return c == target }
+	}
+
+	path := g.botPath([2]int{0, 0}, g.botCanEnter, goal([2]int{0, 4}))
+	if len(path) != 12 || path[len(path)-1] != [2]int{0, 4} {
+		t.Fatalf("path %v, want 12 cells round the wall to (0,4)", path)
+	}
+	for _, c := range path {
+		if !g.botCanEnter(c) {
+			t.Fatalf("path %v goes through the blocked cell %v", path, c)
+		}
+	}
+
+	if path := g.botPath([2]int{0, 0}, g.botCanEnter, goal([2]int{0, 0})); path == nil || len(path) != 0 {
+		t.Errorf("path to the start cell is %v, want an empty path", path)
+	}
+
+	// Close the rest of the column: with a destructible wall, then with a bomb, in its gap
+	for row := 5; row < g.Rows; row++ {
+		g.Panel[row][2] = "W"
+	}
+	g.Panel[4][2] = "D"
+	if path := g.botPath([2]int{0, 0}, g.botCanEnter, goal([2]int{0, 4})); path != nil {
+		t.Errorf("path %v through a destructible wall, want none", path)
+	}
+	g.Panel[4][2] = ""
+	g.Bombs = []Bomb{testBomb(g, 0, 4, 2, time.Hour)}
+	if path := g.botPath([2]int{0, 0}, g.botCanEnter, goal([2]int{0, 4})); path != nil {
+		t.Errorf("path %v through a bomb, want none", path)
+	}
+}
+
+func TestBotEscapesBlast(t *testing.T) {
+	for _, level := range []string{"easy", "normal", "hard"} {
+		// The bot stands on a bomb of the other player, who is far away
+		g := newBombTestBoard([2]int{5, 5}, [2]int{9, 11})
+		clock := g.now()
+		g.Clock = func() time.Time { return clock }
+		bot := &g.Players[0]
+		bot.IsBot = true
+		bot.UUID = "bot"
+		bot.StepSize = g.Rules.StepSize
+		g.bots = []*Bot{{UUID: "bot", Level: level, settings: BotLevels[level]}}
+		g.Bombs = []Bomb{testBomb(g, 1, 5, 5, g.Rules.BombDelay)}
+
+		for tick := 0; len(g.Bombs) > 0 && tick < 10*g.TickRate; tick++ {
+			clock = clock.Add(time.Second / time.Duration(g.TickRate))
+			g.Tick()
+		}
+		if len(g.Bombs) != 0 {
+			t.Fatalf("%s: the bomb never went off", level)
+		}
+		if g.Players[0].Lives != g.Rules.Lives {
+			t.Errorf("%s: the bot was caught in the blast at (%d,%d)", level, g.Players[0].Row, g.Players[0].Column)
+		}
+	}
+}
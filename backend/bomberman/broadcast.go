@@ -6,23 +6,16 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-
-
 // CheckNameHandler handles HTTP requests to check if a player name is already taken or if the game is started.
 // It expects a 'name' query parameter.
 // Responds with JSON: {"isTaken": true/false, "reason": "..."}
 func (g *GameBoard) CheckNameHandler(w http.ResponseWriter, r *http.Request) {
-	// CORS headers: Allow requests from your frontend origin
-	// In a production environment, replace "http://localhost:8000" with your actual frontend domain.
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8000")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS") // Allow GET and preflight OPTIONS
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type") // Allow Content-Type header
+	setCORSHeaders(w)
 
 	// Handle preflight OPTIONS requests
 	if r.Method == "OPTIONS" {
@@ -61,6 +54,7 @@ func (g *GameBoard) CheckNameHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("CheckNameHandler: Name '%s' cannot join: %s", name, err.Error())
 	} else {
 		response["uuid"] = UUID
+		response["room"] = g.ID
 		w.WriteHeader(http.StatusOK) // 200 OK
 		log.Printf("CheckNameHandler: Name '%s' is available.", name)
 	}
@@ -73,6 +67,14 @@ func (g *GameBoard) CheckNameHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func setCORSHeaders(w http.ResponseWriter) {
+	// CORS headers: Allow requests from your frontend origin
+	// In a production environment, replace "http://localhost:8000" with your actual frontend domain.
+	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8000")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS") // Allow GET and preflight OPTIONS
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type") // Allow Content-Type header
+}
+
 func (g *GameBoard) HandleWSConnections(w http.ResponseWriter, r *http.Request) {
 	log.Println("Handling new WS connection")
 
@@ -96,7 +98,7 @@ func (g *GameBoard) HandleWSConnections(w http.ResponseWriter, r *http.Request)
 
 	g.Mu.Lock()
 	playerIndex := g.GetPlayerByUUID(UUID)
-	if playerIndex == -1 {
+	if playerIndex == -1 || g.Players[playerIndex].IsBot {
 		g.Mu.Unlock()
 		errMsg := fmt.Sprintf("Error finding player with UUID %s", UUID)
 		log.Println(errMsg)
@@ -106,111 +108,212 @@ func (g *GameBoard) HandleWSConnections(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, ok := g.PlayersConnections[playerIndex]; ok {
+		// Only a dropped connection can be resumed: a second socket must not take over a live one
+		g.Mu.Unlock()
+		log.Printf("Player %d is already connected, refusing the new connection\n", playerIndex)
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(1008, "Player is already connected"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
 	g.PlayersConnections[playerIndex] = conn
+	if g.GameState != "lobby" {
+		g.Players[playerIndex].Disconnected = false
+		snapshot := g.Snapshot()
+		history := g.ChatHistoryMsg()
+		log.Printf("Player %s reconnected as player %d\n", g.Players[playerIndex].Name, playerIndex)
+		g.Mu.Unlock()
+
+		g.SendPlayerAccepted(playerIndex)
+		g.SendMsgToPlayer(history, playerIndex)
+		g.SendMsgToPlayer(snapshot, playerIndex)
+		g.SendMsgToChannel(struct {
+			Type  string `json:"type"`
+			Index int    `json:"index"`
+		}{
+			Type:  "PlayerReconnected",
+			Index: playerIndex,
+		}, -1)
+		go g.HandlePlayerMessages(playerIndex, conn)
+		return
+	}
 	log.Printf("Player %s connected successfully as player %d\n", g.Players[playerIndex].Name, playerIndex)
+	playerListMsg := g.PlayerListMsg()
+	history := g.ChatHistoryMsg()
 	g.Mu.Unlock()
 
 	g.SendPlayerAccepted(playerIndex)
+	g.SendMsgToPlayer(history, playerIndex)
 
 	// Send the current list of players to all clients
-	playerListMsg := map[string]interface{}{
-		"type":    "player_list",
-		"players": g.Players,
-	}
 	g.SendMsgToChannel(playerListMsg, -1) // -1 sends to all
 
 	go g.HandlePlayerMessages(playerIndex, conn)
-	var once sync.Once
-	if g.NumberOfPlayers == MinNumberOfPlayers {
+	g.Mu.Lock()
+	// Bots may already fill some slots, so count from the minimum on, but start the countdown only once
+	startCountdown := g.NumberOfPlayers >= MinNumberOfPlayers && !g.countingDown && !g.IsStarted
+	if startCountdown {
+		g.countingDown = true
+	}
+	full := g.NumberOfPlayers == MaxNumberOfPlayers
+	gen := g.countdownGen
+	g.Mu.Unlock()
+	if startCountdown {
 		log.Println("Minimum number of players reached. Starting countdown.")
-		// Start countdown only once
-		once.Do(func() {
-			go g.startCountdown()
-		})
+		go g.startCountdown(gen)
 	}
 
-	if g.NumberOfPlayers == MaxNumberOfPlayers {
+	if full {
 		log.Println("Maximum number of players reached. Forcing game start.")
-		g.forceStartGame()
+		g.forceStartGame(gen)
 	}
 }
 
-func (g *GameBoard) startCountdown() {
+// countdownCanceled reports whether the room was reset since the countdown of generation gen began.
+// Must be called with g.Mu held.
+func (g *GameBoard) countdownCanceled(gen int) bool {
+	return g.countdownGen != gen
+}
+
+// startCountdown runs the lobby countdown, then starts the game. It stops early when
+// a player leaves, and does nothing more once the room was reset after gen was read.
+func (g *GameBoard) startCountdown(gen int) {
+	defer func() {
+		g.Mu.Lock()
+		if !g.countdownCanceled(gen) {
+			g.countingDown = false
+		}
+		g.Mu.Unlock()
+	}()
+
 	stateMsg := StateMsg{
 		Type:  "GameState",
 		State: "LobbyCountdown",
 	}
 	g.SendMsgToChannel(stateMsg, -1)
-	g.GameState = "lobby"
 
-	for i := lobbyCountdownTimer; i > 0; i-- {
+	for i := g.Rules.LobbyCountdown; i > 0; i-- {
+		if g.Closed() {
+			return
+		}
+		g.Mu.Lock()
+		if g.countdownCanceled(gen) {
+			g.Mu.Unlock()
+			return
+		}
 		if g.StopCountdown {
+			g.StopCountdown = false
+			g.Mu.Unlock()
 			stateMsg := StateMsg{
 				Type:  "GameState",
 				State: "StopCountdown",
 			}
 			g.SendMsgToChannel(stateMsg, -1)
-			g.StopCountdown = false
 			return
 		}
+		g.Mu.Unlock()
 		msg := map[string]interface{}{
 			"type":    "lobbyCountdown",
 			"seconds": i,
 		}
 		g.SendMsgToChannel(msg, -1)
 		time.Sleep(1 * time.Second)
-		LobbyMsg = true
 		g.Mu.Lock()
-		if g.NumberOfPlayers == MaxNumberOfPlayers {
+		g.LobbyMsg = true
+		if g.NumberOfPlayers == MaxNumberOfPlayers || g.IsStarted {
 			g.Mu.Unlock()
 			return // Game already started
 		}
 		g.Mu.Unlock()
 	}
 
-	g.forceStartGame()
+	g.forceStartGame(gen)
 }
 
-func (g *GameBoard) forceStartGame() {
-
-	if g.IsStarted {
+// forceStartGame runs the game countdown and starts the match. It gives up as soon as
+// the room is reset after gen was read, so a reset room stays in the lobby.
+func (g *GameBoard) forceStartGame(gen int) {
+	g.Mu.Lock()
+	if g.IsStarted || g.countdownCanceled(gen) {
+		g.Mu.Unlock()
 		return
 	}
 	g.IsStarted = true
+	filled := false
+	if g.BotFill != "" && g.CanCreateNewPlayer() {
+		if err := g.FillWithBots(g.BotFill); err != nil {
+			log.Printf("Room %s: could not fill with bots: %v\n", g.ID, err)
+		}
+		filled = true
+	}
+	if g.isTeamMode() {
+		g.balanceTeams()
+		filled = true
+	}
+	playerListMsg := g.PlayerListMsg()
+	g.startRecording()
+	g.GameState = "gameCountdown"
+	g.Mu.Unlock()
+
+	if filled {
+		g.SendMsgToChannel(playerListMsg, -1)
+	}
 
 	stateMsg := StateMsg{
 		Type:  "GameState",
 		State: "GameCountdown",
 	}
 	g.SendMsgToChannel(stateMsg, -1)
-	g.GameState = "gameCountdown"
 	// 10 seconds to start
-	for i := startCountdownTimer; i > 0; i-- {
+	for i := g.Rules.GameCountdown; i > 0; i-- {
+		if g.Closed() {
+			return
+		}
 		msg := map[string]interface{}{
 			"type":    "gameCountdown",
 			"seconds": i,
 		}
 		g.SendMsgToChannel(msg, -1)
 		time.Sleep(1 * time.Second)
+		g.Mu.Lock()
+		canceled := g.countdownCanceled(gen)
+		g.Mu.Unlock()
+		if canceled {
+			return
+		}
 	}
 
+	g.Mu.Lock()
+	if g.countdownCanceled(gen) {
+		g.Mu.Unlock()
+		return
+	}
+	g.GameState = "gameStarted"
+	g.StartedAt = g.now()
+	g.Mu.Unlock()
 	stateMsg = StateMsg{
 		Type:  "GameState",
 		State: "GameStarted",
 	}
 	g.SendMsgToChannel(stateMsg, -1)
-	g.GameState = "gameStarted"
 	msg := struct {
-		Type            string                                `json:"type"`
-		Players         []Player                              `json:"players"`
-		NumberOfPlayers int                                   `json:"numberOfPlayers"`
-		Panel           [NumberOfRows][NumberOfColumns]string `json:"panel"`
+		Type            string     `json:"type"`
+		Players         []Player   `json:"players"`
+		NumberOfPlayers int        `json:"numberOfPlayers"`
+		Panel           [][]string `json:"panel"`
+		Seed            int64      `json:"seed"`
 	}{
 		Type:            "gameStart",
 		Players:         g.Players,
 		NumberOfPlayers: g.NumberOfPlayers,
-		Panel:           g.Panel,
+		Panel:           copyPanel(g.Panel),
+		Seed:            g.Seed,
 	}
+	log.Printf("Room %s: match started with seed %d\n", g.ID, g.Seed)
 	g.SendMsgToChannel(msg, -1)
+	g.Mu.Lock()
 	g.CheckGameEnd()
+	g.Mu.Unlock()
 }
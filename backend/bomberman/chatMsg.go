@@ -1,31 +1,161 @@
 package bomberman
+
 import (
 	"log"
+	"strings"
 	"time"
 )
 
-func (g *GameBoard) HandleChatMessage(msgMap map[string]interface{}) {
-	var msg Chat
-	playerIndex, ok := msgMap["fromPlayer"].(int)
-	if !ok {
-		log.Println("fromPlayer not found in message")
+// HandleChatMessage sends a chat message to the room, or runs it as a command when it
+// starts with '/': see chatHelp.
+func (g *GameBoard) HandleChatMessage(playerIndex int, content string) {
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	if !g.validPlayer(playerIndex) {
+		log.Printf("Chat message from unknown player %d\n", playerIndex)
+		g.SendError(playerIndex, &ProtocolError{Code: ErrCodeInvalidPlayer, MsgType: "c", Message: "player is not in the game"})
 		return
 	}
 
-	Content, ok := msgMap["content"].(string)
-	if !ok {
-		log.Println("fromPlayer not found in message")
+	if notice := g.moderateChat(playerIndex); notice != "" {
+		g.sendSystemChat(playerIndex, notice)
 		return
 	}
-	g.Mu.Lock()
-	msg.Type = "CM" // chat message
-	msg.Name = g.Players[playerIndex].Name
-	msg.Color = g.Players[playerIndex].Color
-	msg.SenderIndex = playerIndex
-	msg.Content = Content
-	msg.Date = time.Now()
+	command, text := parseChatCommand(content)
+	switch command {
+	case "":
+		g.sendPublicChat(playerIndex, ChatKindMessage, content)
+	case "me":
+		if text == "" {
+			g.sendSystemChat(playerIndex, "Usage: /me <action>")
+			return
+		}
+		g.sendPublicChat(playerIndex, ChatKindEmote, text)
+	case "w":
+		g.sendWhisper(playerIndex, text)
+	case "t":
+		g.sendTeamChat(playerIndex, text)
+	case "help":
+		g.sendSystemChat(playerIndex, chatHelp)
+	default:
+		g.sendSystemChat(playerIndex, "Unknown command /"+command+". Type /help for the list of commands.")
+	}
+}
+
+// parseChatCommand splits "/cmd rest" into the lowercase command name and the rest.
+// A message that is not a command gives an empty command.
+func parseChatCommand(content string) (string, string) {
+	if !strings.HasPrefix(content, "/") {
+		return "", content
+	}
+	command, text, _ := strings.Cut(content[1:], " ")
+	return strings.ToLower(command), strings.TrimSpace(text)
+}
+
+// newChat builds a chat message from a player. Must be called with g.Mu held.
+func (g *GameBoard) newChat(playerIndex int, kind, content string) Chat {
+	return Chat{
+		Type:        "CM", // chat message
+		Kind:        kind,
+		Name:        g.Players[playerIndex].Name,
+		Color:       g.Players[playerIndex].Color,
+		SenderIndex: playerIndex,
+		Content:     g.Moderator.Mask(content),
+		Date:        time.Now(),
+	}
+}
+
+// sendPublicChat sends a message or an emote to the whole room. Must be called with g.Mu held.
+func (g *GameBoard) sendPublicChat(playerIndex int, kind, content string) {
+	msg := g.newChat(playerIndex, kind, content)
+	g.logChat(msg)
 	g.SendMsgToChannel(msg, playerIndex)
-	g.Mu.Unlock()
+}
 
+// sendWhisper sends "/w <name> <text>" to the named player and echoes it to the sender.
+// Must be called with g.Mu held.
+func (g *GameBoard) sendWhisper(playerIndex int, args string) {
+	name, text, _ := strings.Cut(args, " ")
+	text = strings.TrimSpace(text)
+	if name == "" || text == "" {
+		g.sendSystemChat(playerIndex, "Usage: /w <name> <message>")
+		return
+	}
+	target := -1
+	for i, player := range g.Players {
+		if strings.EqualFold(player.Name, name) {
+			target = i
+			break
+		}
+	}
+	if target == -1 {
+		g.sendSystemChat(playerIndex, "No player named "+name+" in this room.")
+		return
+	}
+	if target == playerIndex {
+		g.sendSystemChat(playerIndex, "You cannot whisper to yourself.")
+		return
+	}
+	msg := g.newChat(playerIndex, ChatKindWhisper, text)
+	msg.To = g.Players[target].Name
+	g.SendMsgToPlayers(msg, []int{playerIndex, target})
+}
+
+// sendTeamChat sends "/t <text>" to the sender's team only. Must be called with g.Mu held.
+func (g *GameBoard) sendTeamChat(playerIndex int, text string) {
+	if !g.isTeamMode() {
+		g.sendSystemChat(playerIndex, "Team chat is only available in team mode.")
+		return
+	}
+	if text == "" {
+		g.sendSystemChat(playerIndex, "Usage: /t <message>")
+		return
+	}
+	var team []int
+	for i, player := range g.Players {
+		if player.Team == g.Players[playerIndex].Team {
+			team = append(team, i)
+		}
+	}
+	g.SendMsgToPlayers(g.newChat(playerIndex, ChatKindTeam, text), team)
+}
+
+// sendSystemChat answers a player with a chat message from the server only they see.
+func (g *GameBoard) sendSystemChat(playerIndex int, content string) {
+	g.SendMsgToPlayer(Chat{
+		Type:        "CM",
+		Kind:        ChatKindSystem,
+		Content:     content,
+		Date:        time.Now(),
+		SenderIndex: -1,
+	}, playerIndex)
+}
+
+// broadcastSystemChat sends a message from the server to the whole room. Must be called with g.Mu held.
+func (g *GameBoard) broadcastSystemChat(content string) {
+	msg := Chat{
+		Type:        "CM",
+		Kind:        ChatKindSystem,
+		Name:        "Server",
+		Content:     content,
+		Date:        time.Now(),
+		SenderIndex: -1,
+	}
+	g.logChat(msg)
+	g.SendMsgToChannel(msg, -1)
+}
+
+// logChat keeps a chat message for the match transcript and for the players who join
+// later, dropping the oldest beyond ChatHistorySize. Must be called with g.Mu held.
+func (g *GameBoard) logChat(msg Chat) {
+	g.matchChat = append(g.matchChat, msg)
+	g.chatLog = append(g.chatLog, msg)
+	if len(g.chatLog) > ChatHistorySize {
+		g.chatLog = append([]Chat(nil), g.chatLog[len(g.chatLog)-ChatHistorySize:]...)
+	}
+}
 
-}
\ No newline at end of file
+// ChatHistoryMsg builds the message replaying the recent chat to one player. Must be called with g.Mu held.
+func (g *GameBoard) ChatHistoryMsg() ChatHistoryMsg {
+	return ChatHistoryMsg{Type: "ChatHistory", Messages: append([]Chat{}, g.chatLog...)}
+}
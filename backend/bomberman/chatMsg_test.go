@@ -0,0 +1,82 @@
+package bomberman
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChatCommand(t *testing.T) {
+	for content, want := range map[string][2]string{
+		"hello":            {"", "hello"},
+		"/me waves":        {"me", "waves"},
+		"/W bob  hi there": {"w", "bob  hi there"},
+		"/help":            {"help", ""},
+		"a /t not a cmd":   {"", "a /t not a cmd"},
+	} {
+		command, text := parseChatCommand(content)
+		if command != want[0] || text != want[1] {
+			t.Errorf("parseChatCommand(%q) = %q, %q, want %q, %q", content, command, text, want[0], want[1])
+		}
+	}
+}
+
+// sentChats drains the room's broadcast channel, giving each chat message with the
+// players it goes to (nil for everyone).
+func sentChats(g *GameBoard) (chats []Chat, to [][]int) {
+	for {
+		select {
+		case msg := <-g.BroadcastChannel:
+			var players []int
+			switch m := msg.(type) {
+			case targetedMsg:
+				players, msg = m.Players, m.Msg
+			case sequencedMsg:
+				msg = m.Msg
+			}
+			if chat, ok := msg.(Chat); ok {
+				chats = append(chats, chat)
+				to = append(to, players)
+			}
+		default:
+			return chats, to
+		}
+	}
+}
+
+func TestChatCommands(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		teams   bool
+		kind    string
+		text    string
+		to      []int
+	}{
+		{"hello all", false, ChatKindMessage, "hello all", nil},
+		{"/me waves", false, ChatKindEmote, "waves", nil},
+		{"/w C psst", false, ChatKindWhisper, "psst", []int{0, 2}},
+		{"/w nobody psst", false, ChatKindSystem, "No player named nobody in this room.", []int{0}},
+		{"/t go left", true, ChatKindTeam, "go left", []int{0, 1}},
+		{"/t go left", false, ChatKindSystem, "Team chat is only available in team mode.", []int{0}},
+		{"/dance", false, ChatKindSystem, "Unknown command /dance. Type /help for the list of commands.", []int{0}},
+	} {
+		g := newTeamTestBoard([2]int{1, 1}, [2]int{1, 11}, [2]int{9, 1}, [2]int{9, 11})
+		if !tc.teams {
+			g.Mode = ModeFreeForAll
+		}
+
+		g.HandleChatMessage(0, tc.content)
+
+		chats, to := sentChats(g)
+		if len(chats) != 1 {
+			t.Errorf("%q: sent %d chat messages, want 1", tc.content, len(chats))
+			continue
+		}
+		if chats[0].Kind != tc.kind || chats[0].Content != tc.text || !reflect.DeepEqual(to[0], tc.to) {
+			t.Errorf("%q: sent %s %q to %v, want %s %q to %v", tc.content, chats[0].Kind, chats[0].Content, to[0], tc.kind, tc.text, tc.to)
+		}
+		// Only what the whole room sees is kept for the history and the transcript
+		if logged := len(g.chatLog) == 1; logged != (tc.to == nil) {
+			t.Errorf("%q: logged = %v, want %v", tc.content, logged, tc.to == nil)
+		}
+	}
+}
@@ -1,31 +1,86 @@
 package bomberman
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 func (g *GameBoard) StartBroadcaster() {
-	for msg := range g.BroadcastChannel {
+	for {
+		var msg interface{}
+		select {
+		case msg = <-g.BroadcastChannel:
+		case <-g.quit:
+			log.Printf("Room %s closed, exiting broadcaster\n", g.ID)
+			return
+		}
 		g.Mu.Lock()
 		conns := make(map[int]*websocket.Conn)
 		for k, v := range g.PlayersConnections {
 			conns[k] = v
 		}
+		spectators := make(map[int]*websocket.Conn)
+		for k, v := range g.SpectatorConnections {
+			spectators[k] = v
+		}
+		recorder := g.recorder
 		g.Mu.Unlock()
+		seq := 0
+		switch m := msg.(type) {
+		case targetedMsg:
+			msg = m.Msg
+			conns = pickConnections(conns, m.Players)
+			spectators = pickConnections(spectators, m.Spectators)
+		case sequencedMsg:
+			msg = m.Msg
+			seq = m.Seq
+		}
+		data, err := encodeMsg(msg, seq)
+		if err != nil {
+			log.Printf("Error encoding message: %v\n", err)
+			continue
+		}
+		if seq != 0 && recorder != nil {
+			recorder.Record("out", -1, data)
+		}
+		for _, msgType := range msgTypesOf(data) {
+			metrics.countOut(msgType, len(conns)+len(spectators))
+		}
 		for playerIndex, conn := range conns {
 			// check for the chat messages sender
-			msg := CheckForPlayer(msg, playerIndex)
-			err := conn.WriteJSON(msg)
+			data, err := encodeMsg(CheckForPlayer(msg, playerIndex), seq)
+			if err != nil {
+				log.Printf("Error encoding message for player %d: %v\n", playerIndex, err)
+				continue
+			}
+			err = conn.WriteMessage(websocket.TextMessage, data)
 			if err != nil {
+				// Closing makes the player's read loop exit and run the disconnect logic
 				log.Printf("Broadcast error to player %d: %v\n", playerIndex, err)
 				conn.Close()
-				delete(g.PlayersConnections, playerIndex)
 			}
 		}
+		for spectatorID, conn := range spectators {
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				log.Printf("Broadcast error to spectator %d: %v\n", spectatorID, err)
+				conn.Close()
+			}
+		}
+	}
+}
+
+func pickConnections(conns map[int]*websocket.Conn, ids []int) map[int]*websocket.Conn {
+	picked := make(map[int]*websocket.Conn)
+	for _, id := range ids {
+		if conn, ok := conns[id]; ok {
+			picked[id] = conn
+		}
 	}
-	log.Println("Broadcaster channel closed, exiting broadcaster")
+	return picked
 }
 
 func CheckForPlayer(msg interface{}, playerIndex int) interface{} {
@@ -46,32 +101,65 @@ func CheckForPlayer(msg interface{}, playerIndex int) interface{} {
 	}
 	return msgMap
 }
+
+// HandlePlayerMessages reads a player's messages until their connection closes. The
+// player's slot is looked up for every message, since lobby slots move when a player leaves.
 func (g *GameBoard) HandlePlayerMessages(playerIndex int, conn *websocket.Conn) {
 
 	for {
-		var msg map[string]interface{}
-		err := conn.ReadJSON(&msg)
+		_, data, err := conn.ReadMessage()
 		if err != nil {
 			log.Printf("Error reading from player %d: %v\n", playerIndex, err)
 			break
 		}
-		// Tag message with player index
-		msg["fromPlayer"] = playerIndex
-		g.ChooseHandlerForMessages(msg)
+		g.Mu.Lock()
+		index := g.connIndex(conn)
+		g.Mu.Unlock()
+		if index == -1 {
+			break // Kicked or replaced by a newer connection
+		}
+		playerIndex = index
+		msg, perr := ParseClientMessage(data)
+		if perr != nil {
+			metrics.countIn("invalid")
+			log.Printf("Rejected message from player %d: %v\n", playerIndex, perr)
+			g.SendError(playerIndex, perr)
+			continue
+		}
+		metrics.countIn(clientMsgType(data))
+		g.Mu.Lock()
+		recorder := g.recorder
+		g.Mu.Unlock()
+		if recorder != nil {
+			recorder.Record("in", playerIndex, data)
+		}
+		g.handleClientMessage(playerIndex, msg)
 	}
 	log.Printf("Player %d disconnected\n", playerIndex)
-	delete(g.PlayersConnections, playerIndex)
 
 	g.Mu.Lock()
+	playerIndex = g.connIndex(conn)
+	if playerIndex == -1 {
+		// The player was kicked or already reconnected on a newer connection, nothing to clean up
+		g.Mu.Unlock()
+		conn.Close()
+		return
+	}
+	delete(g.PlayersConnections, playerIndex)
+
+	inGrace := false
+	var moved []int
 	if playerIndex < len(g.Players) {
 		switch g.GameState {
-		case "gameStarted":
-			log.Printf("Player %d lives before disconnect: %d\n", playerIndex, g.Players[playerIndex].Lives)
-			g.PlayerDeath(playerIndex)
+		case "gameStarted", "gameCountdown":
+			if !g.Players[playerIndex].IsDead {
+				log.Printf("Player %d lost connection, holding the slot for %v\n", playerIndex, ReconnectGracePeriod)
+				g.startReconnectGrace(playerIndex)
+				inGrace = true
+			}
 		case "lobby":
 			log.Printf("Player %d disconnect before game start\n", playerIndex)
-			g.Players = append(g.Players[:playerIndex], g.Players[playerIndex+1:]...)
-			g.NumberOfPlayers--
+			moved = g.removeLobbyPlayer(playerIndex)
 			g.StopCountdown = true
 		default:
 			g.Players[playerIndex].IsDead = true
@@ -80,6 +168,34 @@ func (g *GameBoard) HandlePlayerMessages(playerIndex int, conn *websocket.Conn)
 	}
 	g.Mu.Unlock()
 
+	if inGrace {
+		g.SendMsgToChannel(ConnectionLostMsg{
+			Type:         "PlayerConnectionLost",
+			Index:        playerIndex,
+			GraceSeconds: int(ReconnectGracePeriod / time.Second),
+		}, -1)
+	} else {
+		g.sendPlayerDisconnected(playerIndex)
+	}
+	for _, index := range moved {
+		g.SendPlayerAccepted(index)
+	}
+
+	conn.Close()
+	log.Printf("Connection closed for player %d\n", playerIndex)
+}
+
+// connIndex returns the slot of the player connected on conn, or -1. Must be called with g.Mu held.
+func (g *GameBoard) connIndex(conn *websocket.Conn) int {
+	for index, c := range g.PlayersConnections {
+		if c == conn {
+			return index
+		}
+	}
+	return -1
+}
+
+func (g *GameBoard) sendPlayerDisconnected(playerIndex int) {
 	g.SendMsgToChannel(struct {
 		Type  string `json:"type"`
 		Index int    `json:"index"`
@@ -87,53 +203,85 @@ func (g *GameBoard) HandlePlayerMessages(playerIndex int, conn *websocket.Conn)
 		Type:  "PlayerDisconnected",
 		Index: playerIndex,
 	}, -1)
+}
 
-	conn.Close()
-	log.Printf("Connection closed for player %d\n", playerIndex)
+// startReconnectGrace keeps a dropped player's slot alive for ReconnectGracePeriod.
+// If the player has not reconnected by then, they are removed from the match.
+// Must be called with g.Mu held.
+func (g *GameBoard) startReconnectGrace(playerIndex int) {
+	player := &g.Players[playerIndex]
+	player.Disconnected = true
+	player.DisconnectedAt = time.Now()
+	player.IsMoving = false
+
+	disconnectedAt := player.DisconnectedAt
+	time.AfterFunc(ReconnectGracePeriod, func() {
+		g.Mu.Lock()
+		if g.Closed() || playerIndex >= len(g.Players) {
+			g.Mu.Unlock()
+			return
+		}
+		player := &g.Players[playerIndex]
+		if !player.Disconnected || !player.DisconnectedAt.Equal(disconnectedAt) || player.IsDead {
+			g.Mu.Unlock()
+			return
+		}
+		log.Printf("Player %d did not reconnect in time, lives before disconnect: %d\n", playerIndex, player.Lives)
+		g.PlayerDeath(playerIndex, -1, CauseDisconnect)
+		g.Mu.Unlock()
+
+		g.sendPlayerDisconnected(playerIndex)
+	})
 }
+
+// SendMsgToChannel queues a message for the broadcaster. Messages for everyone are
+// numbered here, before the send is attempted, so a dropped message leaves a gap
+// in the sequence that clients can detect.
 func (g *GameBoard) SendMsgToChannel(msg any, playerIndex int) {
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+
+	if _, ok := msg.(targetedMsg); !ok {
+		g.seq++
+		msg = sequencedMsg{Seq: g.seq, Msg: msg}
+	}
+
 	select {
 	case g.BroadcastChannel <- msg:
 		// Message forwarded
 	default:
+		metrics.countDrop()
 		log.Printf("Broadcast channel full, dropped message from player %d\n", playerIndex)
 	}
 }
-func (g *GameBoard) ChooseHandlerForMessages(msg interface{}) {
-	// Step 1: Assert msg is a map[string]interface{}
-	msgMap, ok := msg.(map[string]interface{})
-	if !ok {
-		log.Println("Invalid message format in ChooseHandlerForMessages")
-		return
+
+// LastSeq returns the sequence number of the last message queued for everyone.
+func (g *GameBoard) LastSeq() int {
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+	return g.seq
+}
+
+// encodeMsg marshals a message and, for numbered messages, adds the "seq" field to it.
+func encodeMsg(msg interface{}, seq int) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil || seq == 0 || len(data) < 2 || data[0] != '{' {
+		return data, err
 	}
-	playerIndex, ok := msgMap["fromPlayer"].(int)
-	if !ok {
-		log.Println("fromPlayer not found in message")
-		return
-	}
-	// Step 2: Extract msgType
-	msgType, ok := msgMap["msgType"].(string)
-	if !ok {
-		log.Println("msgType not found or not a string")
-		return
+	prefix := fmt.Sprintf(`{"seq":%d`, seq)
+	if data[1] == '}' {
+		return []byte(prefix + "}"), nil
 	}
+	return append([]byte(prefix+","), data[1:]...), nil
+}
 
-	// Step 3: Switch based on msgType
-	switch msgType {
-	case "MS": // Move Start
-		direction, ok := msgMap["d"].(string)
-		if !ok {
-			log.Println("Invalid or missing direction in MS message")
-			return
-		}
-		g.HandleMoveStartMessage(playerIndex, direction)
-	case "ME": // Move End
-		g.HandleMoveEndMessage(playerIndex)
-	case "b": // Bomb
-		g.HandleBombMessage(msgMap)
-	case "c": // Chat
-		g.HandleChatMessage(msgMap)
-	default:
-		log.Println("Unknown msgType:", msgType)
-	}
+// SendMsgToPlayer queues a message that is delivered only to the given player.
+// It goes through the broadcaster so it stays ordered with the rest of the stream.
+func (g *GameBoard) SendMsgToPlayer(msg any, playerIndex int) {
+	g.SendMsgToChannel(targetedMsg{Players: []int{playerIndex}, Msg: msg}, playerIndex)
+}
+
+// SendMsgToPlayers queues a message that is delivered only to the given players, and to no spectator.
+func (g *GameBoard) SendMsgToPlayers(msg any, players []int) {
+	g.SendMsgToChannel(targetedMsg{Players: players, Msg: msg}, -1)
 }
@@ -0,0 +1,31 @@
+package bomberman
+
+import "testing"
+
+func TestEncodeMsg(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  interface{}
+		seq  int
+		want string
+	}{
+		{"numbered object", map[string]int{"a": 1}, 7, `{"seq":7,"a":1}`},
+		{"numbered empty object", struct{}{}, 3, `{"seq":3}`},
+		{"not numbered", map[string]int{"a": 1}, 0, `{"a":1}`},
+		{"not an object", []int{1, 2}, 4, `[1,2]`},
+		{"string", "hi", 4, `"hi"`},
+	}
+	for _, tt := range tests {
+		data, err := encodeMsg(tt.msg, tt.seq)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, data, tt.want)
+		}
+	}
+
+	if _, err := encodeMsg(func() {}, 1); err == nil {
+		t.Error("encoding a func: want an error")
+	}
+}
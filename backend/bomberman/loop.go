@@ -0,0 +1,124 @@
+package bomberman
+
+import (
+	"log"
+	"time"
+)
+
+// StartGameLoop runs the room's simulation at g.TickRate until the room is closed.
+func (g *GameBoard) StartGameLoop() {
+	if g.TickRate <= 0 {
+		g.TickRate = DefaultTickRate
+	}
+	tickRate := g.TickRate
+
+	go func() {
+		ticker := time.NewTicker(time.Second / time.Duration(tickRate))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-g.quit:
+				log.Printf("Room %s closed, exiting game loop\n", g.ID)
+				return
+			}
+			start := time.Now()
+			g.Tick()
+			metrics.observeTick(time.Since(start))
+		}
+	}()
+}
+
+// Tick advances the simulation by one step. The order is fixed: bot decisions, buffered inputs,
+// movement, sliding bombs, explosions, fire, respawns, fire damage and sudden death, then a periodic Delta.
+// Everything the step produces goes out as a single batch.
+func (g *GameBoard) Tick() {
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+
+	g.TickCount++
+	g.runBots()
+	g.processInputs()
+	g.advanceMovement()
+	g.advanceSlidingBombs()
+	g.checkBombs()
+	g.ClearExpiredExplosions()
+	g.ProcessRespawns()
+	g.PeriodicPlayerDamageCheck()
+	g.advanceSuddenDeath()
+	if g.GameState == "gameStarted" && g.TickCount%DeltaInterval == 0 {
+		g.emitDelta()
+	}
+	g.flushUpdates()
+}
+
+func (g *GameBoard) queueInput(input PlayerInput) {
+	g.Mu.Lock()
+	if !g.validPlayer(input.PlayerIndex) {
+		g.Mu.Unlock()
+		log.Printf("Input %s from unknown player %d\n", input.Type, input.PlayerIndex)
+		g.SendError(input.PlayerIndex, &ProtocolError{Code: ErrCodeInvalidPlayer, MsgType: input.Type, Message: "player is not in the game"})
+		return
+	}
+	g.inputs = append(g.inputs, input)
+	g.Mu.Unlock()
+}
+
+// processInputs applies the inputs buffered since the last tick, in arrival order.
+func (g *GameBoard) processInputs() {
+	inputs := g.inputs
+	g.inputs = nil
+
+	for _, input := range inputs {
+		if !g.validPlayer(input.PlayerIndex) {
+			continue
+		}
+		switch input.Type {
+		case "MS":
+			g.applyMoveStart(input.PlayerIndex, input.Direction)
+		case "ME":
+			g.applyMoveEnd(input.PlayerIndex)
+		case "b":
+			g.applyBomb(input.PlayerIndex)
+		case "detonate":
+			g.applyDetonate(input.PlayerIndex)
+		}
+	}
+}
+
+// emit adds a simulation update to the batch sent at the end of the current tick.
+// Must be called with g.Mu held.
+func (g *GameBoard) emit(msg interface{}) {
+	g.pendingUpdates = append(g.pendingUpdates, msg)
+}
+
+func (g *GameBoard) flushUpdates() {
+	if len(g.pendingUpdates) == 0 {
+		return
+	}
+	g.SendMsgToChannel(BatchMsg{
+		Type:    "batch",
+		Tick:    g.TickCount,
+		Updates: g.pendingUpdates,
+	}, -1)
+	g.pendingUpdates = nil
+}
+
+// stepSize scales the player's speed to the room's tick rate, so a player covers
+// the same distance per second whatever the tick rate is.
+func (g *GameBoard) stepSize(player *Player) int {
+	step := player.StepSize * referenceTickRate / g.TickRate
+	if step < 1 {
+		return 1
+	}
+	return step
+}
+
+// now returns the current simulation time: the room's Clock when it has one, the wall clock otherwise.
+func (g *GameBoard) now() time.Time {
+	if g.Clock != nil {
+		return g.Clock()
+	}
+	return time.Now()
+}
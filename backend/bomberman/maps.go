@@ -0,0 +1,189 @@
+package bomberman
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Map files are plain text, one line per board row, one character per cell:
+//
+//	.        empty cell
+//	W        indestructible wall
+//	D        destructible wall
+//	1-4      spawn point of the first to fourth player (an empty cell)
+//	b r l s  visible powerup on an empty cell: ExtraBomb, BombRange, ExtraLife, SpeedBoost
+//	k c      visible powerup on an empty cell: Kick, Remote
+//	p o g    visible powerup on an empty cell: Pierce, BombPass, WallPass
+//
+// Blank lines and lines starting with '#' are ignored. All rows have the same length, and the
+// board is MinBoardSize to MaxBoardSize cells in each direction.
+var mapPowerupSymbols = map[rune]string{
+	'b': "ExtraBomb",
+	'r': "BombRange",
+	'l': "ExtraLife",
+	's': "SpeedBoost",
+	'k': "Kick",
+	'c': "Remote",
+	'p': "Pierce",
+	'o': "BombPass",
+	'g': "WallPass",
+}
+
+// ParseMap reads and validates a map in the text format above.
+func ParseMap(name string, r io.Reader) (*GameMap, error) {
+	m := &GameMap{
+		Name:   name,
+		Spawns: make([][2]int, MaxNumberOfPlayers),
+	}
+	spawnFound := make([]bool, MaxNumberOfPlayers)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		row := len(m.Cells)
+		if row >= MaxBoardSize {
+			return nil, fmt.Errorf("map %s: more than %d rows", name, MaxBoardSize)
+		}
+		cells := []rune(line)
+		if row == 0 && (len(cells) < MinBoardSize || len(cells) > MaxBoardSize) {
+			return nil, fmt.Errorf("map %s: rows must have %d to %d cells, got %d", name, MinBoardSize, MaxBoardSize, len(cells))
+		}
+		if row > 0 && len(cells) != len(m.Cells[0]) {
+			return nil, fmt.Errorf("map %s: row %d has %d cells, expected %d", name, row, len(cells), len(m.Cells[0]))
+		}
+
+		rowCells := make([]string, len(cells))
+		for col, symbol := range cells {
+			switch {
+			case symbol == '.':
+			case symbol == 'W' || symbol == 'D':
+				rowCells[col] = string(symbol)
+			case symbol >= '1' && symbol <= '0'+MaxNumberOfPlayers:
+				index := int(symbol - '1')
+				if spawnFound[index] {
+					return nil, fmt.Errorf("map %s: spawn %c appears more than once", name, symbol)
+				}
+				spawnFound[index] = true
+				m.Spawns[index] = [2]int{row, col}
+			case mapPowerupSymbols[symbol] != "":
+				powerupType := mapPowerupSymbols[symbol]
+				m.Powerups = append(m.Powerups, Powerup{
+					Type:   powerupType,
+					Value:  PowerupValue(powerupType),
+					Row:    row,
+					Column: col,
+				})
+			default:
+				return nil, fmt.Errorf("map %s: unknown cell %q at row %d, column %d", name, symbol, row, col)
+			}
+		}
+		m.Cells = append(m.Cells, rowCells)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("map %s: %v", name, err)
+	}
+
+	if len(m.Cells) < MinBoardSize {
+		return nil, fmt.Errorf("map %s: has %d rows, expected at least %d", name, len(m.Cells), MinBoardSize)
+	}
+	for i, found := range spawnFound {
+		if !found {
+			return nil, fmt.Errorf("map %s: spawn %d is missing", name, i+1)
+		}
+	}
+	if err := m.checkSpawnsReachable(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkSpawnsReachable makes sure every spawn can reach the first one.
+// Destructible walls count as open since they can be blown up.
+func (m *GameMap) checkSpawnsReachable() error {
+	rows, cols := len(m.Cells), len(m.Cells[0])
+	visited := make([][]bool, rows)
+	for i := range visited {
+		visited[i] = make([]bool, cols)
+	}
+
+	start := m.Spawns[0]
+	queue := [][2]int{start}
+	visited[start[0]][start[1]] = true
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			row, col := cell[0]+d[0], cell[1]+d[1]
+			if row < 0 || row >= rows || col < 0 || col >= cols || visited[row][col] || m.Cells[row][col] == "W" {
+				continue
+			}
+			visited[row][col] = true
+			queue = append(queue, [2]int{row, col})
+		}
+	}
+
+	for i, spawn := range m.Spawns {
+		if !visited[spawn[0]][spawn[1]] {
+			return fmt.Errorf("map %s: spawn %d cannot be reached from spawn 1", m.Name, i+1)
+		}
+	}
+	return nil
+}
+
+// LoadMaps loads every *.txt map in dir, keyed by file name without the extension.
+// A missing directory means no custom maps. Any invalid map is an error.
+func LoadMaps(dir string) (map[string]*GameMap, error) {
+	maps := make(map[string]*GameMap)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ParseMap(name, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		maps[name] = m
+		log.Printf("Loaded map %s\n", name)
+	}
+	return maps, nil
+}
+
+// ApplyMap lays out the board from a map: size, cells, spawn points and pre-placed powerups.
+func (g *GameBoard) ApplyMap(m *GameMap) {
+	g.SetSize(len(m.Cells), len(m.Cells[0]))
+	for row := range m.Cells {
+		for col := range m.Cells[row] {
+			g.Panel[row][col] = m.Cells[row][col]
+		}
+	}
+	g.Spawns = append([][2]int(nil), m.Spawns...)
+	g.Powerups = append([]Powerup{}, m.Powerups...)
+	g.PrintPanel()
+}
+
+// MapNames returns the names of the loaded maps, sorted.
+func (m *RoomManager) MapNames() []string {
+	names := make([]string, 0, len(m.Maps))
+	for name := range m.Maps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,158 @@
+package bomberman
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{"valid", []string{
+			"1.....2",
+			".W.W.W.",
+			"..D.b..",
+			".W.W.W.",
+			"...k...",
+			".W.W.W.",
+			"3.....4",
+		}, ""},
+		{"comments and blank lines", []string{
+			"# arena",
+			"1.....2",
+			"",
+			".......",
+			".......",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, ""},
+		{"spawn walled in", []string{
+			"1W....2",
+			"WW.....",
+			".......",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, "cannot be reached"},
+		{"spawn behind destructible walls", []string{
+			"1D....2",
+			"DD.....",
+			".......",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, ""},
+		{"missing spawn", []string{
+			"1.....2",
+			".......",
+			".......",
+			".......",
+			".......",
+			".......",
+			"3......",
+		}, "spawn 4 is missing"},
+		{"duplicate spawn", []string{
+			"1.....2",
+			".......",
+			"...1...",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, "more than once"},
+		{"spawn out of range", []string{
+			"1.....2",
+			".......",
+			"...5...",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, "unknown cell"},
+		{"unknown cell", []string{
+			"1.....2",
+			".......",
+			"...X...",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, "unknown cell"},
+		{"uneven rows", []string{
+			"1.....2",
+			"........",
+			".......",
+			".......",
+			".......",
+			".......",
+			"3.....4",
+		}, "row 1 has 8 cells"},
+		{"too narrow", []string{
+			"1...2",
+			".....",
+			".....",
+			".....",
+			".....",
+			".....",
+			"3...4",
+		}, "rows must have"},
+		{"too wide", []string{
+			"1" + strings.Repeat(".", MaxBoardSize) + "2",
+		}, "rows must have"},
+		{"too few rows", []string{
+			"1.....2",
+			".......",
+			"3.....4",
+		}, "at least"},
+		{"too many rows", append([]string{"1.....2", "3.....4"}, strings.Split(strings.Repeat(".......\n", MaxBoardSize), "\n")...), "more than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMap("test", strings.NewReader(strings.Join(tt.lines, "\n")))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(m.Spawns) != MaxNumberOfPlayers {
+					t.Fatalf("%d spawns, want %d", len(m.Spawns), MaxNumberOfPlayers)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMapContent(t *testing.T) {
+	m, err := ParseMap("test", strings.NewReader(strings.Join([]string{
+		"1.....2",
+		".W.W.W.",
+		"..D.b..",
+		".W.W.W.",
+		"...k...",
+		".W.W.W.",
+		"3.....4",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Cells[1][1] != "W" || m.Cells[2][2] != "D" || m.Cells[0][0] != "" {
+		t.Fatalf("unexpected cells %v", m.Cells[:3])
+	}
+	if m.Spawns[3] != [2]int{6, 6} {
+		t.Fatalf("spawn 4 at %v, want [6 6]", m.Spawns[3])
+	}
+	if len(m.Powerups) != 2 || m.Powerups[0].Type != "ExtraBomb" || m.Powerups[1].Type != "Kick" {
+		t.Fatalf("unexpected powerups %+v", m.Powerups)
+	}
+}
@@ -0,0 +1,215 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Server metrics, served by MetricsHandler in the Prometheus text format. Counters are
+// kept in the package-level metrics; gauges are read from the rooms at scrape time.
+
+var metrics = newMetrics()
+
+func newMetrics() *Metrics {
+	return &Metrics{
+		messagesIn:       make(map[string]uint64),
+		messagesOut:      make(map[string]uint64),
+		matchesCompleted: make(map[string]uint64),
+		tickSamples:      make([]time.Duration, 0, TickSamples),
+	}
+}
+
+// countIn counts a message received from a player.
+func (m *Metrics) countIn(msgType string) {
+	m.mu.Lock()
+	m.messagesIn[msgType]++
+	m.mu.Unlock()
+}
+
+// countOut counts a message written to n connections.
+func (m *Metrics) countOut(msgType string, n int) {
+	m.mu.Lock()
+	m.messagesOut[msgType] += uint64(n)
+	m.mu.Unlock()
+}
+
+func (m *Metrics) countDrop() {
+	m.mu.Lock()
+	m.broadcastDrops++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) countBomb() {
+	m.mu.Lock()
+	m.bombsExploded++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) countMatch(mode string) {
+	m.mu.Lock()
+	m.matchesCompleted[mode]++
+	m.mu.Unlock()
+}
+
+// observeTick records how long one tick of a game loop took.
+func (m *Metrics) observeTick(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.tickSamples) < TickSamples {
+		m.tickSamples = append(m.tickSamples, d)
+	} else {
+		m.tickSamples[m.nextSample] = d
+	}
+	m.nextSample = (m.nextSample + 1) % TickSamples
+	m.tickSum += d
+	m.tickCount++
+}
+
+// snapshot copies the counters.
+func (m *Metrics) snapshot() metricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return metricsSnapshot{
+		messagesIn:       copyCounts(m.messagesIn),
+		messagesOut:      copyCounts(m.messagesOut),
+		broadcastDrops:   m.broadcastDrops,
+		bombsExploded:    m.bombsExploded,
+		matchesCompleted: copyCounts(m.matchesCompleted),
+		tickSamples:      append([]time.Duration(nil), m.tickSamples...),
+		tickSum:          m.tickSum,
+		tickCount:        m.tickCount,
+	}
+}
+
+func copyCounts(counts map[string]uint64) map[string]uint64 {
+	copied := make(map[string]uint64, len(counts))
+	for k, n := range counts {
+		copied[k] = n
+	}
+	return copied
+}
+
+// msgTypesOf returns the "type" or "MT" field of an encoded server message. A batch
+// gives the types of the updates it carries instead of its own.
+func msgTypesOf(data []byte) []string {
+	var envelope struct {
+		Type    string            `json:"type"`
+		MT      string            `json:"MT"`
+		Updates []json.RawMessage `json:"updates"`
+	}
+	json.Unmarshal(data, &envelope)
+	switch {
+	case envelope.Type == "batch":
+		types := make([]string, 0, len(envelope.Updates))
+		for _, update := range envelope.Updates {
+			types = append(types, msgTypesOf(update)...)
+		}
+		return types
+	case envelope.Type != "":
+		return []string{envelope.Type}
+	case envelope.MT != "":
+		return []string{envelope.MT}
+	}
+	return []string{"unknown"}
+}
+
+// clientMsgType returns the msgType of a valid client message.
+func clientMsgType(data []byte) string {
+	var envelope struct {
+		MsgType string `json:"msgType"`
+	}
+	json.Unmarshal(data, &envelope)
+	return envelope.MsgType
+}
+
+// MetricsHandler serves the server metrics in the Prometheus text format.
+func (m *RoomManager) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	m.Mu.Lock()
+	rooms := make([]*GameBoard, 0, len(m.Rooms))
+	for _, g := range m.Rooms {
+		rooms = append(rooms, g)
+	}
+	m.Mu.Unlock()
+
+	playerConns, spectatorConns, queueDepth := 0, 0, 0
+	roomsByState := make(map[string]int)
+	players := map[string]int{"alive": 0, "dead": 0, "spectating": 0}
+	for _, g := range rooms {
+		g.Mu.Lock()
+		playerConns += len(g.PlayersConnections)
+		spectatorConns += len(g.SpectatorConnections)
+		roomsByState[g.GameState]++
+		for _, player := range g.Players {
+			if player.IsDead {
+				players["dead"]++
+			} else {
+				players["alive"]++
+			}
+		}
+		players["spectating"] += len(g.SpectatorConnections)
+		g.Mu.Unlock()
+		queueDepth += len(g.BroadcastChannel)
+	}
+	counters := metrics.snapshot()
+
+	writeMetric(w, "bomberman_connections", "gauge", "Open WebSocket connections.", map[string]float64{
+		`kind="player"`:    float64(playerConns),
+		`kind="spectator"`: float64(spectatorConns),
+	})
+	writeMetric(w, "bomberman_rooms", "gauge", "Rooms by game state.", labelled("state", roomsByState))
+	writeMetric(w, "bomberman_players", "gauge", "Players in the rooms, bots included, alive or dead, and spectators.", labelled("state", players))
+	writeMetric(w, "bomberman_broadcast_queue_depth", "gauge", "Messages waiting in the broadcast channels of all rooms.", map[string]float64{"": float64(queueDepth)})
+
+	writeMetric(w, "bomberman_messages_in_total", "counter", "Messages received from players, by msgType.", labelled("type", counters.messagesIn))
+	writeMetric(w, "bomberman_messages_out_total", "counter", "Messages written to connections, by type, counting each update of a batch.", labelled("type", counters.messagesOut))
+	writeMetric(w, "bomberman_broadcast_dropped_total", "counter", "Messages dropped because a broadcast channel was full.", map[string]float64{"": float64(counters.broadcastDrops)})
+	writeMetric(w, "bomberman_bombs_exploded_total", "counter", "Bombs exploded.", map[string]float64{"": float64(counters.bombsExploded)})
+	writeMetric(w, "bomberman_matches_completed_total", "counter", "Matches played to the end, by mode.", labelled("mode", counters.matchesCompleted))
+
+	samples := counters.tickSamples
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	quantiles := make(map[string]float64)
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		value := 0.0
+		if len(samples) > 0 {
+			value = samples[int(q*float64(len(samples)-1))].Seconds()
+		}
+		quantiles[fmt.Sprintf(`quantile="%g"`, q)] = value
+	}
+	writeMetric(w, "bomberman_tick_duration_seconds", "summary", fmt.Sprintf("Time spent in a game loop tick, quantiles over the last %d ticks.", TickSamples), quantiles)
+	fmt.Fprintf(w, "bomberman_tick_duration_seconds_sum %g\n", counters.tickSum.Seconds())
+	fmt.Fprintf(w, "bomberman_tick_duration_seconds_count %d\n", counters.tickCount)
+}
+
+// writeMetric writes one metric family. The keys of values are the label pairs of each
+// sample, empty for a sample without labels.
+func writeMetric(w io.Writer, name, kind, help string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	labels := make([]string, 0, len(values))
+	for l := range values {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	for _, l := range labels {
+		if l == "" {
+			fmt.Fprintf(w, "%s %g\n", name, values[l])
+		} else {
+			fmt.Fprintf(w, "%s{%s} %g\n", name, l, values[l])
+		}
+	}
+}
+
+// labelled turns counts keyed by a label value into samples for writeMetric.
+func labelled[N int | uint64](label string, counts map[string]N) map[string]float64 {
+	values := make(map[string]float64, len(counts))
+	for value, n := range counts {
+		values[fmt.Sprintf("%s=%q", label, value)] = float64(n)
+	}
+	return values
+}
@@ -0,0 +1,94 @@
+package bomberman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgTypesOf(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{`{"seq":3,"type":"GameState","state":"lobby"}`, []string{"GameState"}},
+		{`{"MT":"PB","ID":1}`, []string{"PB"}},
+		{`{"seq":4,"type":"batch","tick":9,"updates":[{"MT":"PB"},{"type":"KillFeed"},{"MT":"EC"}]}`, []string{"PB", "KillFeed", "EC"}},
+		{`{"type":"batch","tick":9,"updates":[]}`, []string{}},
+		{`[1,2]`, []string{"unknown"}},
+	}
+	for _, tt := range tests {
+		if got := msgTypesOf([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("msgTypesOf(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestMetricsPlayersByState(t *testing.T) {
+	m := NewRoomManager()
+	g, err := m.CreateRoom(RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.RemoveRoom(g.ID)
+	g.Mu.Lock()
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := g.CreatePlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Players[1].IsDead = true
+	g.Mu.Unlock()
+
+	w := httptest.NewRecorder()
+	m.MetricsHandler(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		`bomberman_players{state="alive"} 2`,
+		`bomberman_players{state="dead"} 1`,
+		`bomberman_players{state="spectating"} 0`,
+		`bomberman_rooms{state="lobby"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), want+"\n") {
+			t.Errorf("metrics miss %q:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+// blockingWriter is a client that reads the response very slowly.
+type blockingWriter struct {
+	http.ResponseWriter
+	release chan struct{}
+}
+
+func (w blockingWriter) Write(data []byte) (int, error) {
+	<-w.release
+	return w.ResponseWriter.Write(data)
+}
+
+func TestSlowMetricsScrapeDoesNotBlockTicks(t *testing.T) {
+	m := NewRoomManager()
+	w := blockingWriter{httptest.NewRecorder(), make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.MetricsHandler(w, httptest.NewRequest("GET", "/metrics", nil))
+	}()
+	time.Sleep(50 * time.Millisecond) // The handler is stuck on its first write
+
+	observed := make(chan struct{})
+	go func() {
+		metrics.observeTick(time.Millisecond)
+		metrics.countIn("b")
+		close(observed)
+	}()
+	select {
+	case <-observed:
+	case <-time.After(time.Second):
+		t.Error("a tick could not record its duration while /metrics was being written")
+	}
+	close(w.release)
+	<-done
+}
@@ -1,6 +1,11 @@
 package bomberman
 
 import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"regexp"
 	"sync"
 	"time"
 
@@ -9,15 +14,14 @@ import (
 )
 
 // Game.go
-const NumberOfRows = 11
-const NumberOfColumns = 13
+const DefaultNumberOfRows = 11
+const DefaultNumberOfColumns = 13
+const MinBoardSize = 7 // Smallest number of rows or columns, leaves room for the four spawn zones
+const MaxBoardSize = 31
 const MaxNumberOfPlayers = 4
 const MinNumberOfPlayers = 2
 const CellSize = 50
 
-var lobbyCountdownTimer = 20 // 20 for production
-var startCountdownTimer = 10 // 10 for production
-
 var Colors = []string{"G", "Y", "R", "B"}
 
 type PlayerRespawn struct {
@@ -26,20 +30,64 @@ type PlayerRespawn struct {
 }
 
 type GameBoard struct {
-	Players            []Player                              `json:"players"`
-	Bombs              []Bomb                                `json:"bombs"`
-	PendingRespawns    []PlayerRespawn                       `json:"-"`
-	NumberOfPlayers    int                                   `json:"numberOfPlayers"`
-	Panel              [NumberOfRows][NumberOfColumns]string `json:"panel"` // Ex -> Explode , W -> Wall, D -> Destructible, ""(empty) -> empty cell, B -> Bomb
-	CellSize           int                                   `json:"cellSize"`
-	Powerups           []Powerup                             `json:"powerups"`
-	IsStarted          bool
-	GameState          string // lobby, gameCountdown, gameStarted
-	StopCountdown      bool
-	ExplodedCells      []ExplodedCellInfo `json:"explodedCells"`
-	PlayersConnections map[int]*websocket.Conn
-	powerupChosen      map[string]int
-	BroadcastChannel   chan interface{}
+	ID                   string          `json:"id"`
+	Players              []Player        `json:"players"`
+	Bombs                []Bomb          `json:"bombs"`
+	PendingRespawns      []PlayerRespawn `json:"-"`
+	NumberOfPlayers      int             `json:"numberOfPlayers"`
+	Rows                 int             `json:"rows"`
+	Columns              int             `json:"columns"`
+	Panel                [][]string      `json:"panel"` // Ex -> Explode , W -> Wall, D -> Destructible, ""(empty) -> empty cell, B -> Bomb
+	CellSize             int             `json:"cellSize"`
+	Powerups             []Powerup       `json:"powerups"`
+	IsStarted            bool
+	GameState            string // lobby, gameCountdown, gameStarted
+	StopCountdown        bool
+	ExplodedCells        []ExplodedCellInfo      `json:"explodedCells"`
+	PlayersConnections   map[int]*websocket.Conn `json:"-"`
+	SpectatorConnections map[int]*websocket.Conn `json:"-"`
+	nextSpectatorID      int
+	powerupChosen        map[string]int
+	BroadcastChannel     chan interface{} `json:"-"`
+	LobbyMsg             bool             `json:"-"`
+	CreatedAt            time.Time        `json:"createdAt"`
+	OnFinished           func(*GameBoard) `json:"-"`      // Called once the match is over instead of resetting the board
+	Spawns               [][2]int         `json:"spawns"` // Start cell (row, column) of each player slot
+	Map                  *GameMap         `json:"-"`      // Custom map the board is built from, nil for a random board
+	MapName              string           `json:"map"`
+	Mode                 string           `json:"mode"`         // ModeFreeForAll or ModeTeams
+	FriendlyFire         bool             `json:"friendlyFire"` // Team mode: bombs hurt teammates too
+	Rules                Rules            `json:"-"`
+	Stats                MatchStats       `json:"-"`
+	Clock                func() time.Time `json:"-"` // Simulation time source, nil for the wall clock
+	StartedAt            time.Time        `json:"-"` // Simulation time the match started
+	suddenDeathCells     [][2]int         // Cells still to be filled once sudden death began, in landing order
+	nextBlock            time.Time        // When the next sudden death block lands
+	nextBombID           int
+	bots                 []*Bot
+	BotFill              string `json:"-"` // Bot level that fills the empty slots when the match starts, empty to leave them
+	countingDown         bool   // A lobby countdown is running
+	countdownGen         int    // Bumped by ResetGame so running countdowns give up
+	Seed                 int64  `json:"seed"` // Seed of rng, logged and sent in gameStart so a match can be rerun
+	rng                  *rand.Rand
+	TickRate             int `json:"tickRate"` // Simulation ticks per second
+	TickCount            int `json:"tickCount"`
+	inputs               []PlayerInput
+	pendingUpdates       []interface{}
+	ReplayDir            string                `json:"-"` // Where match replays are written, empty to disable
+	Profiles             *ProfileStore         `json:"-"` // Where the match results are saved, nil to keep none
+	Transcripts          *TranscriptStore      `json:"-"` // Where the match chat is kept, nil to keep none
+	chatLog              []Chat                // Recent chat, at most ChatHistorySize messages
+	matchChat            []Chat                // Every chat message since the room last reset, for the transcript
+	Moderator            *ChatModerator        `json:"-"` // Word filter and server-wide mutes, nil for none
+	chatMutes            map[string]time.Time  // Room mutes by lowercased player name
+	chatLimits           map[string]*chatLimit // Chat rate limits by player name
+	recorder             *Recorder
+	deltaBase            *SnapshotMsg // Board state the next Delta is computed against
+	seq                  int          // Last sequence number handed out, guarded by sendMu
+	sendMu               sync.Mutex
+	quit                 chan struct{}
+	closeOnce            sync.Once
 
 	Mu sync.Mutex
 }
@@ -51,9 +99,37 @@ type GameCell struct {
 	IsExploaded    bool `json:"isExploaded"`
 }
 
+// loop.go
+const DefaultTickRate = 20   // 50ms per tick
+const referenceTickRate = 20 // Tick rate the player step sizes are tuned for
+
+// PlayerInput is a client intent buffered until the next tick of the game loop.
+type PlayerInput struct {
+	PlayerIndex int
+	Type        string // MS, ME, b or detonate
+	Direction   string
+}
+
+// BatchMsg carries every update produced during one tick.
+type BatchMsg struct {
+	Type    string        `json:"type"`
+	Tick    int           `json:"tick"`
+	Updates []interface{} `json:"updates"`
+}
+
 // bomb.go
 const BombExplosionDuration = 1 * time.Second
-const PlayerInvulnerabilityDuration = 1 * time.Second // How long player is invulnerable after respawn
+const BombSlideStep = 10 // Pixels per reference tick a kicked bomb slides
+
+// BombMoveMsg gives the new position of a sliding bomb.
+type BombMoveMsg struct {
+	MsgType   string `json:"MT"`
+	ID        int    `json:"ID"`
+	Row       int    `json:"R"`
+	Column    int    `json:"C"`
+	XLocation int    `json:"XL"`
+	YLocation int    `json:"YL"`
+}
 
 type Position struct {
 	Row        int  `json:"row"`
@@ -69,11 +145,17 @@ type Bomb struct {
 	ExplosionTime       time.Time `json:"explosionTime"`
 	OwnPlayerIndex      int       `json:"playerIndex"`
 	InitialIntersection bool      `json:"initialIntersection"`
+	ID                  int       `json:"id"`
+	Sliding             string    `json:"sliding"` // Direction of a kicked bomb, empty when it stands still
+	Remote              bool      `json:"remote"`  // No timer: explodes when its owner detonates it
+	Origin              int       `json:"origin"`  // Player credited with the explosion: the owner, or whoever set off the chain
+	Chained             bool      `json:"chained"` // Set off by another player's blast
 }
 
 type ExplodedCellInfo struct {
 	Position  Position
 	ClearTime time.Time // When this cell should revert from "Ex" to ""
+	Origin    int       // Index of the player credited with the fire, see Bomb.Origin
 }
 
 type ExploadeCellsMsg struct {
@@ -96,6 +178,8 @@ type PLayerDeath struct {
 
 type PlantBomb struct {
 	MsgType     string `json:"MT"`
+	ID          int    `json:"ID"`
+	Remote      bool   `json:"RM"`
 	XLocation   int    `json:"XL"`
 	YLocation   int    `json:"YL"`
 	Row         int    `json:"R"`
@@ -103,7 +187,28 @@ type PlantBomb struct {
 	PlayerIndex int    `json:"PI"`
 }
 
+// Causes of a lost life, sent in KillFeedMsg
+const (
+	CauseOwnBomb      = "ownBomb"
+	CauseOpponentBomb = "opponentBomb"
+	CauseFire         = "fire" // Walked into a burning cell
+	CauseDisconnect   = "disconnect"
+	CauseCrushed      = "crushed" // Under a sudden death block
+	CauseChain        = "chain"   // A bomb set off by another player's blast
+)
+
+// KillFeedMsg announces a life lost: who took it, from whom and how. Killer is -1 when nobody did.
+type KillFeedMsg struct {
+	Type   string `json:"type"`
+	Killer int    `json:"killer"`
+	Victim int    `json:"victim"`
+	Cause  string `json:"cause"`
+	Fatal  bool   `json:"fatal"` // The victim is out of the match
+}
+
 // broadcast.go
+const ReconnectGracePeriod = 15 * time.Second // How long a dropped player's slot survives during a match
+
 var Upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all connections
@@ -119,12 +224,285 @@ type StateMsg struct {
 	State string `json:"state"`
 }
 
-var LobbyMsg bool
+// PlayerListMsg is sent to everyone in the lobby whenever a player joins or changes team.
+type PlayerListMsg struct {
+	Type    string   `json:"type"`
+	Players []Player `json:"players"`
+	Mode    string   `json:"mode"`
+	Teams   [][]int  `json:"teams,omitempty"` // Player indexes of team 1 and team 2, in team mode only
+}
+
+// room.go
+const RoomIdleTimeout = 2 * time.Minute // Empty lobbies older than this are removed
+const RoomCleanupInterval = 30 * time.Second
+const MaxRooms = 100 // Rooms open at once, creating more is refused
+
+var ErrTooManyRooms = errors.New("too many rooms are open, try again later")
+
+type RoomManager struct {
+	Rooms       map[string]*GameBoard
+	Rules       Rules               // Rules of new rooms, before their own overrides
+	Maps        map[string]*GameMap // Custom maps by name
+	TickRate    int                 // Tick rate for new rooms
+	ReplayDir   string              // Replay directory for new rooms
+	Profiles    *ProfileStore       // Where match results are saved, nil to keep none
+	Transcripts *TranscriptStore    // Chat of the finished matches
+	Moderator   *ChatModerator      // Chat moderation for every room
+	AdminToken  string              // Secret of the admin API, empty to disable it
+	Mu          sync.Mutex
+}
+
+// RoomOptions are the settings a room can be created with.
+type RoomOptions struct {
+	Seed         int64  // 0 picks a random seed
+	Map          string // Name of a custom map, empty for a random board
+	Rows         int    // Board size of a random board, 0 for the default. A map brings its own size.
+	Columns      int
+	Rules        RuleOverrides // Changes to the server rules for this room only
+	Bots         int           // Bots added to the lobby right away
+	BotFill      bool          // Fill the empty slots with bots when the match starts
+	BotLevel     string
+	Mode         string // ModeFreeForAll or ModeTeams, empty for free-for-all
+	FriendlyFire bool   // Team mode: bombs hurt teammates too
+}
+
+type RoomInfo struct {
+	ID              string    `json:"id"`
+	State           string    `json:"state"`
+	NumberOfPlayers int       `json:"numberOfPlayers"`
+	MaxPlayers      int       `json:"maxPlayers"`
+	Spectators      int       `json:"spectators"`
+	Seed            int64     `json:"seed"`
+	Map             string    `json:"map"`
+	Rows            int       `json:"rows"`
+	Columns         int       `json:"columns"`
+	Bots            int       `json:"bots"`
+	Mode            string    `json:"mode"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// bot.go
+const DefaultBotLevel = "normal"
+const botStuckTicks = 10 // Ticks without moving before a bot gives up on its path
+
+type Bot struct {
+	UUID         string
+	Level        string
+	settings     BotSettings
+	path         [][2]int // Cells (row, column) still to walk through
+	nextDecision int      // Tick of the next goal decision
+	stuck        int
+	lastX, lastY int
+}
+
+type BotSettings struct {
+	thinkInterval int     // Ticks between two goal decisions
+	slowReaction  bool    // Only notices danger when it makes a decision
+	wander        float64 // Chance to make a random step instead of going for a goal
+	collect       bool    // Goes for visible powerups
+	hunt          bool    // Chases and bombs opponents
+}
+
+var BotLevels = map[string]BotSettings{
+	"easy":   {thinkInterval: 10, slowReaction: true, wander: 0.3},
+	"normal": {thinkInterval: 5, wander: 0.1, collect: true, hunt: true},
+	"hard":   {thinkInterval: 1, collect: true, hunt: true},
+}
+
+// suddendeath.go
+const botCollapseWarning = 2 * time.Second // Bots stay off cells a block lands on this soon
+
+// BlockMsg announces a sudden death block. Any bomb or powerup on its cell is gone.
+type BlockMsg struct {
+	Type string `json:"type"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+}
+
+// profiles.go
+const DefaultLeaderboardSize = 10
+const MaxLeaderboardSize = 100
+
+// ProfileStore keeps the player profiles in a JSON file.
+type ProfileStore struct {
+	path     string
+	profiles map[string]*Profile
+	mu       sync.Mutex
+}
+
+type Profile struct {
+	Name        string    `json:"name"`
+	Wins        int       `json:"wins"`
+	Losses      int       `json:"losses"`
+	Kills       int       `json:"kills"`
+	Deaths      int       `json:"deaths"`
+	GamesPlayed int       `json:"gamesPlayed"`
+	LastPlayed  time.Time `json:"lastPlayed"`
+}
+
+// MatchRecord is one player's result in a finished match.
+type MatchRecord struct {
+	Name   string
+	Won    bool
+	Draw   bool
+	Kills  int
+	Deaths int
+}
+
+// team.go
+const ModeFreeForAll = "ffa"
+const ModeTeams = "teams"
+const NumberOfTeams = 2
+const MaxTeamSize = MaxNumberOfPlayers / NumberOfTeams
+
+// simulate.go
+// MatchStats counts what happened during a match, by cause or powerup type.
+type MatchStats struct {
+	LivesLost    map[string]int `json:"livesLost"`
+	Eliminations map[string]int `json:"eliminations"`
+	Powerups     map[string]int `json:"powerups"`
+}
+
+type SimOptions struct {
+	Seed        int64
+	Map         *GameMap // nil for a random board
+	Rows        int      // Random board size, 0 for the default
+	Columns     int
+	Rules       Rules
+	TickRate    int
+	BotLevels   []string      // One bot per entry, 2 to MaxNumberOfPlayers
+	MaxDuration time.Duration // Simulated time after which the match is called a draw
+}
+
+type MatchResult struct {
+	Seed     int64      `json:"seed"`
+	Winner   int        `json:"winner"` // Player index, which is also the spawn slot, -1 for a draw
+	Spawns   [][2]int   `json:"spawns"`
+	Ticks    int        `json:"ticks"`
+	Duration float64    `json:"duration"` // Simulated seconds
+	TimedOut bool       `json:"timedOut"`
+	Stats    MatchStats `json:"stats"`
+}
+
+// rules.go
+type Rules struct {
+	Lives               int
+	StartingBombs       int
+	BombRange           int
+	BombDelay           time.Duration // Time between placing a bomb and its explosion
+	StepSize            int           // Pixels per reference tick
+	LobbyCountdown      int           // Seconds
+	GameCountdown       int           // Seconds
+	PowerupDropChance   float64       // Chance that a destroyed wall drops a powerup
+	Invulnerability     time.Duration // How long a player is invulnerable after respawn
+	SuddenDeath         time.Duration // Match time before the arena starts shrinking, 0 to disable
+	SuddenDeathInterval time.Duration // Time between two blocks landing
+	KillPoints          int           // Score for taking a life from an opponent
+	SelfKillPoints      int           // Score for losing a life to your own or a teammate's blast
+	WallPoints          int           // Score for each destructible wall destroyed
+}
+
+// RuleOverrides maps rule names, as accepted by Rules.Set, to their new value.
+type RuleOverrides map[string]string
+
+// RuleNames lists every rule name with its description.
+var RuleNames = [][2]string{
+	{"lives", "lives of each player"},
+	{"bombs", "bombs a player can place at once at the start"},
+	{"range", "starting bomb range in cells"},
+	{"bombDelay", "time before a bomb explodes, e.g. 3s"},
+	{"step", "starting movement step in pixels"},
+	{"lobbyCountdown", "lobby countdown in seconds"},
+	{"gameCountdown", "countdown before the match in seconds"},
+	{"dropChance", "chance from 0 to 1 that a destroyed wall drops a powerup"},
+	{"invulnerability", "invulnerability after a respawn, e.g. 1s"},
+	{"suddenDeath", "match time before the arena starts shrinking, e.g. 2m, 0 to disable"},
+	{"suddenDeathInterval", "time between two sudden death blocks, e.g. 500ms"},
+	{"killPoints", "points for taking a life from an opponent"},
+	{"selfKillPoints", "points for a life lost to your own bomb or taken from a teammate"},
+	{"wallPoints", "points for each destructible wall destroyed"},
+}
+
+// maps.go
+type GameMap struct {
+	Name     string
+	Cells    [][]string // W, D or "" (empty)
+	Spawns   [][2]int   // Start cell (row, column) of each player slot
+	Powerups []Powerup
+}
+
+// replay.go
+const MaxReplays = 200 // Replay files kept in the replay directory, the oldest are deleted first
+
+// Recorder writes every numbered broadcast and every accepted input of a match to a JSONL file.
+type Recorder struct {
+	ID      string
+	file    *os.File
+	encoder *json.Encoder
+	start   time.Time
+	mu      sync.Mutex
+}
+
+// ReplayEvent is one line of a replay file.
+type ReplayEvent struct {
+	Time        int64           `json:"t"`    // Milliseconds since the recording started
+	Kind        string          `json:"kind"` // "out" for broadcasts, "in" for player inputs
+	PlayerIndex int             `json:"player"`
+	Msg         json.RawMessage `json:"msg"`
+}
+
+// snapshot.go
+const DeltaInterval = 20 // Ticks between two Delta messages
+
+type SnapshotMsg struct {
+	Type            string     `json:"type"`
+	Seq             int        `json:"seq"` // Last numbered message already reflected in this snapshot
+	State           string     `json:"state"`
+	Seed            int64      `json:"seed"`
+	Players         []Player   `json:"players"`
+	NumberOfPlayers int        `json:"numberOfPlayers"`
+	Panel           [][]string `json:"panel"`
+	Bombs           []Bomb     `json:"bombs"`
+	Powerups        []Powerup  `json:"powerups"`
+}
+
+type CellChange struct {
+	Row   int    `json:"row"`
+	Col   int    `json:"col"`
+	Value string `json:"value"`
+}
+
+// DeltaMsg lists what changed since the previous Delta. Bombs and Powerups are
+// full lists, null when they did not change.
+type DeltaMsg struct {
+	Type     string       `json:"type"`
+	Tick     int          `json:"tick"`
+	Cells    []CellChange `json:"cells,omitempty"`
+	Players  []Player     `json:"players,omitempty"`
+	Bombs    []Bomb       `json:"bombs"`
+	Powerups []Powerup    `json:"powerups"`
+}
 
 // chatMsg.go
+const ChatHistorySize = 50 // Chat messages kept per room for late joiners
+const MaxTranscripts = 100 // Finished match transcripts kept in memory
+
+// Kinds of chat messages
+const (
+	ChatKindMessage = ""        // Message to the whole room
+	ChatKindEmote   = "emote"   // "/me" action, shown as "<name> <content>"
+	ChatKindWhisper = "whisper" // "/w" message, seen only by the sender and the player in To
+	ChatKindTeam    = "team"    // "/t" message, seen only by the sender's team
+	ChatKindSystem  = "system"  // Answer from the server to one player
+)
+
+const chatHelp = "Commands: /w <name> <message> whispers to a player, /t <message> talks to your team, /me <action> describes an action, /help shows this list."
+
 type Chat struct {
 	Type        string    `json:"type"`
+	Kind        string    `json:"kind,omitempty"`
 	Name        string    `json:"name"`
+	To          string    `json:"to,omitempty"` // Recipient of a whisper
 	Content     string    `json:"content"`
 	Date        time.Time `json:"date"`
 	Filter      bool      `json:"filter"`
@@ -132,9 +510,173 @@ type Chat struct {
 	Color       string    `json:"color"`
 }
 
+type ChatHistoryMsg struct {
+	Type     string `json:"type"`
+	Messages []Chat `json:"messages"`
+}
+
+// TranscriptStore keeps the chat of the last finished matches, oldest first.
+type TranscriptStore struct {
+	transcripts []Transcript
+	mu          sync.Mutex
+}
+
+// Transcript is the chat of one finished match, from the lobby to the end.
+type Transcript struct {
+	ID       string    `json:"id"` // Same as the match's replay ID when it was recorded
+	Room     string    `json:"room"`
+	EndedAt  time.Time `json:"endedAt"`
+	Messages []Chat    `json:"messages"`
+}
+
+// admin.go
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminPlayerInfo describes a player in the admin player list.
+type AdminPlayerInfo struct {
+	Room      string `json:"room"`
+	Index     int    `json:"index"`
+	Name      string `json:"name"`
+	UUID      string `json:"uuid"`
+	Connected bool   `json:"connected"`
+	IsBot     bool   `json:"isBot"`
+	IsDead    bool   `json:"isDead"`
+	Lives     int    `json:"lives"`
+	Team      int    `json:"team,omitempty"`
+}
+
+// metrics.go
+const TickSamples = 1024 // Recent ticks the tick duration quantiles are computed over
+
+// Metrics holds the server counters.
+type Metrics struct {
+	messagesIn       map[string]uint64
+	messagesOut      map[string]uint64
+	broadcastDrops   uint64
+	bombsExploded    uint64
+	matchesCompleted map[string]uint64
+	tickSamples      []time.Duration // Ring of the last TickSamples tick durations
+	nextSample       int
+	tickSum          time.Duration
+	tickCount        uint64
+	mu               sync.Mutex
+}
+
+// metricsSnapshot is a copy of the counters, taken so a scrape does not hold Metrics.mu while writing.
+type metricsSnapshot struct {
+	messagesIn       map[string]uint64
+	messagesOut      map[string]uint64
+	broadcastDrops   uint64
+	bombsExploded    uint64
+	matchesCompleted map[string]uint64
+	tickSamples      []time.Duration
+	tickSum          time.Duration
+	tickCount        uint64
+}
+
+// moderation.go
+const MaxChatLength = 200 // Characters in a chat message
+const ChatBurst = 5       // Chat messages a player can send at once
+const ChatRefill = time.Second
+const ChatFloodStrikes = 3 // Messages refused in a row before a flooder is muted
+const ChatFloodMute = 30 * time.Second
+
+// ChatModerator holds the moderation settings shared by every room.
+type ChatModerator struct {
+	banned *regexp.Regexp       // Banned words, nil when there are none
+	mutes  map[string]time.Time // Server-wide mutes by lowercase name, zero time for no end
+	mu     sync.Mutex
+}
+
+// chatLimit is a player's chat rate limit bucket.
+type chatLimit struct {
+	tokens   float64
+	refilled time.Time
+	strikes  int
+}
+
+// gameMsg.go
+// targetedMsg travels through the BroadcastChannel like any other message,
+// but the broadcaster only delivers it to the listed players.
+type targetedMsg struct {
+	Players    []int
+	Spectators []int
+	Msg        interface{}
+}
+
+// sequencedMsg is a message for everyone, numbered by SendMsgToChannel.
+type sequencedMsg struct {
+	Seq int
+	Msg interface{}
+}
+
+type ConnectionLostMsg struct {
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	GraceSeconds int    `json:"graceSeconds"`
+}
+
+// protocol.go
+const (
+	ErrCodeBadJSON       = "bad_json"
+	ErrCodeMissingType   = "missing_type"
+	ErrCodeUnknownType   = "unknown_type"
+	ErrCodeInvalidField  = "invalid_field"
+	ErrCodeInvalidPlayer = "invalid_player"
+	ErrCodeNotAllowed    = "not_allowed"
+)
+
+type MoveStartMsg struct {
+	MsgType   string `json:"msgType"`
+	Direction string `json:"d"`
+}
+
+type MoveEndMsg struct {
+	MsgType string `json:"msgType"`
+}
+
+type BombMsg struct {
+	MsgType string `json:"msgType"`
+}
+
+type DetonateMsg struct {
+	MsgType string `json:"msgType"`
+}
+
+type ChatMsg struct {
+	MsgType string `json:"msgType"`
+	Content string `json:"content"`
+}
+
+type ResyncMsg struct {
+	MsgType string `json:"msgType"`
+}
+
+type TeamMsg struct {
+	MsgType string `json:"msgType"`
+	Team    int    `json:"team"`
+}
+
+// ProtocolError describes why a client message was rejected.
+type ProtocolError struct {
+	Code    string
+	MsgType string
+	Message string
+}
+
+type ErrorMsg struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	MsgType string `json:"msgType,omitempty"`
+	Message string `json:"message"`
+}
+
 // move.go
 const movementTolerance = 20
 
+// directionOffsets maps a direction to its (row, column) step.
+var directionOffsets = map[string][2]int{"u": {-1, 0}, "d": {1, 0}, "l": {0, -1}, "r": {0, 1}}
+
 type MovePlayerMsg struct {
 	MsgType     string `json:"MT"`
 	XLocation   int    `json:"XL"`
@@ -144,9 +686,6 @@ type MovePlayerMsg struct {
 }
 
 // player.go
-const StepSize = 5
-const BombDelay = 3
-const BombRange = 2
 const PlayerSize = 48
 
 type Player struct {
@@ -154,6 +693,8 @@ type Player struct {
 	Name              string        `json:"name"`
 	Lives             int           `json:"lives"`
 	Score             int           `json:"score"`
+	Kills             int           `json:"kills"`  // Lives taken from opponents
+	Deaths            int           `json:"deaths"` // Lives lost
 	Color             string        `json:"color"`
 	Row               int           `json:"row"`
 	Column            int           `json:"column"`
@@ -172,8 +713,17 @@ type Player struct {
 	IsMoving          bool          `json:"isMoving"`
 	JustRespawned     bool          `json:"justRespawned"`
 	LastDamageTime    time.Time     `json:"lastDamageTime"`
-	UUID              string        `json:"uuid"`
-	StopMoveChan      chan struct{} `json:"-"` // Channel to signal the player's movement goroutine to stop
+	UUID              string        `json:"-"` // Secret that resumes the slot, sent only to its player in PlayerAccepted
+	Disconnected      bool          `json:"disconnected"`
+	DisconnectedAt    time.Time     `json:"-"`
+	InvulnerableUntil time.Time     `json:"-"`
+	IsBot             bool          `json:"isBot"`
+	CanKick           bool          `json:"canKick"`   // Walking into a bomb sends it sliding
+	HasRemote         bool          `json:"hasRemote"` // Bombs wait for a detonate message, lost on death
+	Pierce            bool          `json:"pierce"`    // Blasts go through destructible walls
+	BombPass          bool          `json:"bombPass"`  // Walks through bombs
+	WallPass          bool          `json:"wallPass"`  // Walks through destructible walls
+	Team              int           `json:"team"`      // 1 or 2 in team mode, 0 in free-for-all
 }
 
 // powerup.go
@@ -181,7 +731,7 @@ const MaxBombsPowerup = 5
 const MaxBombRangePowerup = 5
 const MaxSpeedPowerup = 20
 
-var PowerupTypes = []string{"ExtraBomb", "BombRange", "ExtraLife", "SpeedBoost"}
+var PowerupTypes = []string{"ExtraBomb", "BombRange", "ExtraLife", "SpeedBoost", "Kick", "Remote", "Pierce", "BombPass", "WallPass"}
 
 type Powerup struct {
 	Type     string `json:"type"`
@@ -0,0 +1,171 @@
+package bomberman
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Chat moderation: every player may send ChatBurst messages at once, then one every
+// ChatRefill. A player who keeps flooding is muted in the room for ChatFloodMute.
+// The ChatModerator masks banned words and keeps the server-wide mutes.
+
+// NewChatModerator creates a moderator masking the given words, case-insensitively.
+func NewChatModerator(bannedWords []string) *ChatModerator {
+	m := &ChatModerator{mutes: make(map[string]time.Time)}
+	var quoted []string
+	for _, word := range bannedWords {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) > 0 {
+		m.banned = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return m
+}
+
+// ReadWordList reads one entry per line from path, skipping blank lines and lines
+// starting with '#'. A missing file gives an empty list.
+func ReadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// Mask replaces every banned word in text with asterisks.
+func (m *ChatModerator) Mask(text string) string {
+	if m == nil || m.banned == nil {
+		return text
+	}
+	return m.banned.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
+
+// Mute silences a player in every room. A duration of 0 mutes them until Unmute.
+func (m *ChatModerator) Mute(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until := time.Time{}
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	m.mutes[strings.ToLower(name)] = until
+	if d > 0 {
+		log.Printf("Player %s muted on the server for %v\n", name, d)
+	} else {
+		log.Printf("Player %s muted on the server\n", name)
+	}
+}
+
+// Unmute lifts a player's server-wide mute.
+func (m *ChatModerator) Unmute(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.mutes, strings.ToLower(name))
+	log.Printf("Player %s unmuted on the server\n", name)
+}
+
+// IsMuted reports whether a player is muted on the server.
+func (m *ChatModerator) IsMuted(name string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.mutes[strings.ToLower(name)]
+	if !ok {
+		return false
+	}
+	if !until.IsZero() && time.Now().After(until) {
+		delete(m.mutes, strings.ToLower(name))
+		return false
+	}
+	return true
+}
+
+// MutePlayer silences a player in this room for d, matching the name case-insensitively.
+// A duration of 0 mutes them until UnmutePlayer or the room resets. Must be called with g.Mu held.
+func (g *GameBoard) MutePlayer(name string, d time.Duration) {
+	if g.chatMutes == nil {
+		g.chatMutes = make(map[string]time.Time)
+	}
+	if d > 0 {
+		g.chatMutes[strings.ToLower(name)] = time.Now().Add(d)
+		log.Printf("Room %s: player %s muted for %v\n", g.ID, name, d)
+	} else {
+		g.chatMutes[strings.ToLower(name)] = time.Time{}
+		log.Printf("Room %s: player %s muted\n", g.ID, name)
+	}
+}
+
+// UnmutePlayer lifts a player's mute in this room. Must be called with g.Mu held.
+func (g *GameBoard) UnmutePlayer(name string) {
+	delete(g.chatMutes, strings.ToLower(name))
+	log.Printf("Room %s: player %s unmuted\n", g.ID, name)
+}
+
+// moderateChat decides whether a player may send a chat message now. It returns an
+// empty string when they may, or the notice to send back. Must be called with g.Mu held.
+func (g *GameBoard) moderateChat(playerIndex int) string {
+	name := g.Players[playerIndex].Name
+	now := time.Now()
+	if g.Moderator.IsMuted(name) {
+		return "You are muted on this server."
+	}
+	if until, ok := g.chatMutes[strings.ToLower(name)]; ok {
+		if until.IsZero() {
+			return "You are muted in this room."
+		}
+		if now.Before(until) {
+			return "You are muted for " + until.Sub(now).Round(time.Second).String() + "."
+		}
+		delete(g.chatMutes, strings.ToLower(name))
+	}
+
+	if g.chatLimits == nil {
+		g.chatLimits = make(map[string]*chatLimit)
+	}
+	limit := g.chatLimits[name]
+	if limit == nil {
+		limit = &chatLimit{tokens: ChatBurst, refilled: now}
+		g.chatLimits[name] = limit
+	}
+	limit.tokens += float64(now.Sub(limit.refilled)) / float64(ChatRefill)
+	if limit.tokens > ChatBurst {
+		limit.tokens = ChatBurst
+	}
+	limit.refilled = now
+	if limit.tokens < 1 {
+		limit.strikes++
+		if limit.strikes >= ChatFloodStrikes {
+			limit.strikes = 0
+			g.MutePlayer(name, ChatFloodMute)
+			return "You were muted for " + ChatFloodMute.String() + " for flooding the chat."
+		}
+		return "You are sending messages too fast."
+	}
+	limit.tokens--
+	limit.strikes = 0
+	return ""
+}
@@ -0,0 +1,20 @@
+package bomberman
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoomMuteIgnoresCase(t *testing.T) {
+	g := newBombTestBoard([2]int{1, 1})
+	g.Players[0].Name = "Bob"
+
+	g.MutePlayer("bob", time.Minute)
+	if notice := g.moderateChat(0); notice == "" {
+		t.Fatal("Bob can chat after bob was muted")
+	}
+	g.UnmutePlayer("BOB")
+	if notice := g.moderateChat(0); notice != "" {
+		t.Fatalf("Bob is still refused after BOB was unmuted: %s", notice)
+	}
+}
@@ -2,16 +2,20 @@ package bomberman
 
 import (
 	"log"
-	"time"
 )
 
-
-
-// HandleMoveStartMessage initiates continuous movement for a player.
+// HandleMoveStartMessage buffers a move start; the game loop applies it on the next tick.
 func (g *GameBoard) HandleMoveStartMessage(playerIndex int, direction string) {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
+	g.queueInput(PlayerInput{PlayerIndex: playerIndex, Type: "MS", Direction: direction})
+}
+
+// HandleMoveEndMessage buffers a move end; the game loop applies it on the next tick.
+func (g *GameBoard) HandleMoveEndMessage(playerIndex int) {
+	g.queueInput(PlayerInput{PlayerIndex: playerIndex, Type: "ME"})
+}
 
+// applyMoveStart starts continuous movement for a player. Must be called with g.Mu held.
+func (g *GameBoard) applyMoveStart(playerIndex int, direction string) {
 	if !g.IsStarted {
 		return
 	}
@@ -37,134 +41,47 @@ func (g *GameBoard) HandleMoveStartMessage(playerIndex int, direction string) {
 
 	player.IsMoving = true
 	player.DirectionFace = direction
-
-	// Start a new movement goroutine only if one isn't already running
-	if player.StopMoveChan == nil {
-		player.StopMoveChan = make(chan struct{}) // Create a new stop channel
-		go g.playerMoveLoop(playerIndex)          // Start the continuous movement loop
-		//log.Printf("Player %d started continuous movement in direction: %s\n", playerIndex, direction)
-	} else {
-		//log.Printf("Player %d already has a movement loop running.\n", playerIndex)
-	}
 }
 
-// HandleMoveEndMessage stops continuous movement for a player.
-func (g *GameBoard) HandleMoveEndMessage(playerIndex int) {
-	g.Mu.Lock()
-	defer g.Mu.Unlock()
-
-	player := &g.Players[playerIndex]
-	if player.IsMoving {
-		player.IsMoving = false
-		// Signal the movement goroutine to stop
-		if player.StopMoveChan != nil {
-			close(player.StopMoveChan)
-			player.StopMoveChan = nil // Mark as closed
-			//log.Printf("Player %d stopped continuous movement.\n", playerIndex)
-		}
-	}
-}
-
-// playerMoveLoop is a goroutine that handles continuous movement for a single player.
-// It checks the player's movement state and updates their position at regular intervals.
-func (g *GameBoard) playerMoveLoop(playerIndex int) {
-	ticker := time.NewTicker(50 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			g.Mu.Lock() // Hold lock for entire operation
-
-			if !g.IsStarted {
-				g.Mu.Unlock()
-				return
-			}
-
-			player := &g.Players[playerIndex]
-			if !player.IsMoving || player.IsDead || player.IsHurt{
-				g.Mu.Unlock()
-				return
-			}
-
-			// NEW LOGIC: Check collision *after* attempting a move.
-			// The current player's cell can be "Ex", but they should still be able to move *from* it.
-			// The actual blocking logic should primarily be within MovePlayer.
-
-			// Perform movement
-			moved := g.MovePlayer(playerIndex, player.DirectionFace)
-			if moved {
-				// Broadcast new position
-				msg := MovePlayerMsg{
-					MsgType:     "M",
-					PlayerIndex: playerIndex,
-					XLocation:   player.XLocation,
-					YLocation:   player.YLocation,
-					Direction:   player.DirectionFace,
-				}
-				g.SendMsgToChannel(msg, playerIndex)
-			} else {
-				// If MovePlayer returns false, it means the player hit an impassable object.
-				player.IsMoving = false
-				if player.StopMoveChan != nil {
-					close(player.StopMoveChan)
-					player.StopMoveChan = nil
-				}
-			}
-
-			g.Mu.Unlock()
-
-		case <-g.Players[playerIndex].StopMoveChan:
-			g.Mu.Lock()
-			player := &g.Players[playerIndex]
-			player.IsMoving = false
-			if player.StopMoveChan != nil {
-				player.StopMoveChan = nil
-			}
-			g.Mu.Unlock()
-			return
-		}
-	}
+// applyMoveEnd stops continuous movement for a player. Must be called with g.Mu held.
+func (g *GameBoard) applyMoveEnd(playerIndex int) {
+	g.Players[playerIndex].IsMoving = false
 }
 
-// HandleMoveMessage (original, now superseded by MS/ME) - Kept for reference or if still used for single moves
-func (g *GameBoard) HandleMoveMessage(msgMap map[string]interface{}) {
-	playerIndex, ok := msgMap["fromPlayer"].(int)
-	if !ok {
-		log.Println("fromPlayer not found in message")
-		return
-	}
-
-	direction, ok := msgMap["D"].(string) // Changed from "d" to "D" for consistency with MS/ME
-	if !ok {
-		log.Println("Invalid or missing direction in move message")
+// advanceMovement moves every moving player one step and emits their new positions.
+// Must be called with g.Mu held.
+func (g *GameBoard) advanceMovement() {
+	if !g.IsStarted {
 		return
 	}
 
-	// g.Mu.Lock() // Consider if a lock is needed here if it's a single, non-looping move
-	// defer g.Mu.Unlock()
-
-	player := &g.Players[playerIndex]
-	if player.IsDead { // Removed the HasExploaded check as "Ex" cells should be traversable.
-		log.Printf("Player %d is dead, cannot move.\n", playerIndex)
-		return
-	}
+	for i := range g.Players {
+		player := &g.Players[i]
+		if !player.IsMoving {
+			continue
+		}
+		if player.IsDead || player.IsHurt || player.Disconnected {
+			player.IsMoving = false
+			continue
+		}
 
-	if g.MovePlayer(playerIndex, direction) { // Assuming MovePlayer updates player's X/Y
-		g.SendMoveMsg(playerIndex)
+		// The current player's cell can be "Ex", but they should still be able to move *from* it.
+		// The actual blocking logic is within MovePlayer.
+		if g.MovePlayer(i, player.DirectionFace) {
+			g.emit(MovePlayerMsg{
+				MsgType:     "M",
+				PlayerIndex: i,
+				XLocation:   player.XLocation,
+				YLocation:   player.YLocation,
+				Direction:   player.DirectionFace,
+			})
+		} else if !player.CanKick || !g.kickBomb(i, player.DirectionFace) {
+			// If MovePlayer returns false, it means the player hit an impassable object.
+			player.IsMoving = false
+		}
 	}
 }
 
-func (g *GameBoard) SendMoveMsg(playerIndex int) {
-	var msg MovePlayerMsg
-	msg.MsgType = "M" // General move update
-	msg.PlayerIndex = playerIndex
-	msg.XLocation = g.Players[playerIndex].XLocation
-	msg.YLocation = g.Players[playerIndex].YLocation
-	msg.Direction = g.Players[playerIndex].DirectionFace
-	g.SendMsgToChannel(msg, playerIndex)
-}
-
 func (g *GameBoard) FindCollision(playerIndex int) string {
 	player := g.Players[playerIndex]
 	cellSize := int(g.CellSize)
@@ -191,7 +108,7 @@ func (g *GameBoard) FindCollision(playerIndex int) string {
 
 	for cell := range cellsToCheck {
 		row, col := cell[0], cell[1]
-		if row >= 0 && row < NumberOfRows && col >= 0 && col < NumberOfColumns {
+		if row >= 0 && row < g.Rows && col >= 0 && col < g.Columns {
 			cellContent := g.Panel[row][col]
 			powerupIndex := g.FindPowerupAt(row, col)
 			if cellContent == "" && powerupIndex != -1 {
@@ -199,7 +116,8 @@ func (g *GameBoard) FindCollision(playerIndex int) string {
 			}
 			// FindCollision should report *all* collisions, including "Ex".
 			// It's up to the *caller* of FindCollision to decide what to do with "Ex".
-			if cellContent != "" { // Report any non-empty cell content
+			// Destructible walls are no obstacle for a player with wall-pass.
+			if cellContent != "" && (cellContent != "D" || !player.WallPass) { // Report any non-empty cell content
 				return cellContent
 			}
 		}
@@ -207,6 +125,9 @@ func (g *GameBoard) FindCollision(playerIndex int) string {
 
 	// Check for bomb collisions
 	for _, bomb := range g.Bombs {
+		if player.BombPass {
+			break // Bombs are no obstacle for a player with bomb-pass
+		}
 		// Only consider active bombs that are not the player's own initial bomb placement
 		if bomb.OwnPlayerIndex == playerIndex && bomb.InitialIntersection {
 			continue // Player can initially pass through their own bomb
@@ -262,7 +183,7 @@ func (g *GameBoard) FindDistanceToBorder(playerIndex int, borderName string) int
 
 func (g *GameBoard) MovePlayer(playerIndex int, direction string) bool {
 	player := &g.Players[playerIndex]
-	step := player.StepSize
+	step := g.stepSize(player)
 	cellSize := int(g.CellSize)
 
 	originalX := player.XLocation
@@ -277,8 +198,8 @@ func (g *GameBoard) MovePlayer(playerIndex int, direction string) bool {
 		}
 	case "d":
 		player.YLocation += step
-		if player.YLocation+PlayerSize > NumberOfRows*cellSize {
-			player.YLocation = NumberOfRows*cellSize - PlayerSize
+		if player.YLocation+PlayerSize > g.Rows*cellSize {
+			player.YLocation = g.Rows*cellSize - PlayerSize
 		}
 	case "l":
 		player.XLocation -= step
@@ -287,8 +208,8 @@ func (g *GameBoard) MovePlayer(playerIndex int, direction string) bool {
 		}
 	case "r":
 		player.XLocation += step
-		if player.XLocation+PlayerSize > NumberOfColumns*cellSize {
-			player.XLocation = NumberOfColumns*cellSize - PlayerSize
+		if player.XLocation+PlayerSize > g.Columns*cellSize {
+			player.XLocation = g.Columns*cellSize - PlayerSize
 		}
 	}
 
@@ -344,13 +265,13 @@ func (g *GameBoard) MovePlayer(playerIndex int, direction string) bool {
 			// Clamp for 1px move
 			if player.YLocation < 0 {
 				player.YLocation = 0
-			} else if player.YLocation+PlayerSize > NumberOfRows*cellSize {
-				player.YLocation = NumberOfRows*cellSize - PlayerSize
+			} else if player.YLocation+PlayerSize > g.Rows*cellSize {
+				player.YLocation = g.Rows*cellSize - PlayerSize
 			}
 			if player.XLocation < 0 {
 				player.XLocation = 0
-			} else if player.XLocation+PlayerSize > NumberOfColumns*cellSize {
-				player.XLocation = NumberOfColumns*cellSize - PlayerSize
+			} else if player.XLocation+PlayerSize > g.Columns*cellSize {
+				player.XLocation = g.Columns*cellSize - PlayerSize
 			}
 
 			// Final collision check for 1px move
@@ -0,0 +1,56 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPassAbilities(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		wall     bool // a destructible wall in the way, a bomb otherwise
+		wallPass bool
+		bombPass bool
+		passes   bool
+	}{
+		{"wall without wall-pass", true, false, false, false},
+		{"wall with wall-pass", true, true, false, true},
+		{"wall with bomb-pass", true, false, true, false},
+		{"bomb without bomb-pass", false, false, false, false},
+		{"bomb with bomb-pass", false, false, true, true},
+		{"bomb with wall-pass", false, true, false, false},
+	} {
+		// Player 0 stands right before [1,2], which holds player 1's bomb or a destructible wall
+		g := newBombTestBoard([2]int{1, 1}, [2]int{9, 11})
+		if tc.wall {
+			g.Panel[1][2] = "D"
+		} else {
+			bomb := testBomb(g, 1, 1, 2, time.Hour)
+			bomb.XLocation, bomb.YLocation = 2*g.CellSize, g.CellSize
+			g.Bombs = []Bomb{bomb}
+		}
+		player := &g.Players[0]
+		player.StepSize, player.WallPass, player.BombPass = 5, tc.wallPass, tc.bombPass
+		player.XLocation = 52
+
+		moved := g.MovePlayer(0, "r") && player.XLocation+PlayerSize > 2*g.CellSize
+
+		if moved != tc.passes {
+			t.Errorf("%s: moved into [1,2] = %v, want %v", tc.name, moved, tc.passes)
+		}
+	}
+}
+
+func TestAbilitiesInPlayerJSON(t *testing.T) {
+	data, err := json.Marshal(Player{Pierce: true, BombPass: true, WallPass: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"pierce":true`, `"bombPass":true`, `"wallPass":true`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("player JSON %s lacks %s", data, field)
+		}
+	}
+}
@@ -2,13 +2,10 @@ package bomberman
 
 import (
 	"errors"
-	"time"
 
 	"github.com/google/uuid"
 )
 
-
-
 func (g *GameBoard) CreatePlayer(name string) (string, error) {
 	var player Player
 	if !g.CanCreateNewPlayer() {
@@ -25,7 +22,7 @@ func (g *GameBoard) CreatePlayer(name string) (string, error) {
 	player.UUID = uuid.New().String()
 	player.Index = g.NumberOfPlayers
 	player.Name = name
-	player.Lives = 3 
+	player.Lives = g.Rules.Lives
 	player.Score = 0
 	player.Color = g.FindColor()
 	player.Row = g.FindStartRowLocation()
@@ -33,13 +30,16 @@ func (g *GameBoard) CreatePlayer(name string) (string, error) {
 	player.InitialRow = player.Row
 	player.InitialColumn = player.Column
 	player.XLocation, player.YLocation = player.Column*int(g.CellSize), player.Row*int(g.CellSize)
-	player.StepSize = StepSize
-	player.BombDelay = BombDelay * time.Second
-	player.BombRange = BombRange
-	player.NumberOfBombs = 3
+	player.StepSize = g.Rules.StepSize
+	player.BombDelay = g.Rules.BombDelay
+	player.BombRange = g.Rules.BombRange
+	player.NumberOfBombs = g.Rules.StartingBombs
 	player.NumberOfUsedBombs = 0
 	player.IsDead = false
 	player.IsHurt = false
+	if g.isTeamMode() {
+		player.Team = g.smallestTeam()
+	}
 	g.Players = append(g.Players, player)
 
 	g.NumberOfPlayers++
@@ -47,6 +47,43 @@ func (g *GameBoard) CreatePlayer(name string) (string, error) {
 	return player.UUID, nil
 }
 
+// removeLobbyPlayer frees a lobby slot and moves every later player down one slot, with
+// the color, spawn and connection of their new slot. It returns the indexes of the players
+// that moved. Must be called with g.Mu held.
+func (g *GameBoard) removeLobbyPlayer(playerIndex int) []int {
+	g.Players = append(g.Players[:playerIndex], g.Players[playerIndex+1:]...)
+	g.NumberOfPlayers--
+	delete(g.PlayersConnections, playerIndex)
+
+	var moved []int
+	for i := playerIndex; i < len(g.Players); i++ {
+		player := &g.Players[i]
+		player.Index = i
+		player.Color = Colors[i]
+		player.Row, player.Column = g.Spawns[i][0], g.Spawns[i][1]
+		player.InitialRow, player.InitialColumn = player.Row, player.Column
+		player.XLocation, player.YLocation = player.Column*g.CellSize, player.Row*g.CellSize
+		if conn, ok := g.PlayersConnections[i+1]; ok {
+			g.PlayersConnections[i] = conn
+			delete(g.PlayersConnections, i+1)
+		}
+		moved = append(moved, i)
+	}
+	return moved
+}
+
+// hasHumanPlayer reports whether anyone but bots took a slot.
+func (g *GameBoard) hasHumanPlayer() bool {
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	for _, player := range g.Players {
+		if !player.IsBot {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *GameBoard) GetPlayerByUUID(UUID string) int {
 	for i, p := range g.Players {
 		if p.UUID == UUID {
@@ -2,11 +2,8 @@ package bomberman
 
 import (
 	"log"
-	"math/rand"
 )
 
-
-
 func (g *GameBoard) ShowPowerup(PowerUpIndex int) {
 	if PowerUpIndex < 0 || PowerUpIndex >= len(g.Powerups) {
 		return
@@ -17,18 +14,17 @@ func (g *GameBoard) ShowPowerup(PowerUpIndex int) {
 	}
 	powerup.IsHidden = false
 	g.Powerups[PowerUpIndex] = powerup
-	g.SendMsgToChannel(struct {
+	g.emit(struct {
 		Type    string  `json:"type"`
 		Powerup Powerup `json:"powerup"`
 	}{
 		Type:    "AddPowerup",
 		Powerup: powerup,
-	}, -1)
+	})
 }
 
 func (g *GameBoard) CreatePowerupWithChance(row, column int) {
-	// 30% chance to create a powerup
-	if rand.Float64() > 0.3 {
+	if g.rng.Float64() > g.Rules.PowerupDropChance {
 		return
 	}
 
@@ -53,7 +49,7 @@ func (g *GameBoard) CreatePowerupWithChance(row, column int) {
 	}
 
 	// Generate a random number within the total weight
-	r := rand.Float64() * totalWeight
+	r := g.rng.Float64() * totalWeight
 
 	// Select the powerup type based on weighted chance
 	var chosenType string
@@ -69,7 +65,7 @@ func (g *GameBoard) CreatePowerupWithChance(row, column int) {
 	// If for some reason no type was chosen (shouldn't happen with correct weights),
 	// fall back to a simple random selection.
 	if chosenType == "" {
-		chosenType = PowerupTypes[rand.Intn(len(PowerupTypes))]
+		chosenType = PowerupTypes[g.rng.Intn(len(PowerupTypes))]
 	}
 
 	// Create the powerup instance
@@ -82,29 +78,29 @@ func (g *GameBoard) CreatePowerupWithChance(row, column int) {
 	// Increment the count for the chosen powerup type
 	g.powerupChosen[powerup.Type]++
 
-	// Assign value based on type
-	switch powerup.Type {
-	case "ExtraBomb":
-		powerup.Value = 1
-	case "BombRange":
-		powerup.Value = 1
-	case "ExtraLife":
-		powerup.Value = 1
-	case "SpeedBoost":
-		powerup.Value = 2
-	}
+	powerup.Value = PowerupValue(powerup.Type)
 
 	log.Println("Powerup added:", powerup)
 	g.Powerups = append(g.Powerups, powerup)
 }
 
+// PowerupValue returns how much a powerup of the given type adds.
+func PowerupValue(powerupType string) int {
+	switch powerupType {
+	case "SpeedBoost":
+		return 2
+	default:
+		return 1
+	}
+}
+
 func (g *GameBoard) RemovePowerup(PowerupIndex int) {
 	if PowerupIndex < 0 || PowerupIndex >= len(g.Powerups) {
 		return
 	}
 	powerup := g.Powerups[PowerupIndex]
 	g.Powerups = append(g.Powerups[:PowerupIndex], g.Powerups[PowerupIndex+1:]...)
-	g.SendMsgToChannel(struct {
+	g.emit(struct {
 		Type   string `json:"type"`
 		Row    int    `json:"row"`
 		Column int    `json:"column"`
@@ -112,7 +108,7 @@ func (g *GameBoard) RemovePowerup(PowerupIndex int) {
 		Type:   "RemovePowerup",
 		Row:    powerup.Row,
 		Column: powerup.Column,
-	}, -1)
+	})
 }
 
 func (g *GameBoard) EatPowerup(playerIndex, PowerupIndex int) {
@@ -124,6 +120,7 @@ func (g *GameBoard) EatPowerup(playerIndex, PowerupIndex int) {
 		return
 	}
 	g.RemovePowerup(PowerupIndex)
+	g.Stats.Powerups[powerup.Type]++
 	player := &g.Players[playerIndex]
 	switch powerup.Type {
 	case "ExtraBomb":
@@ -138,7 +135,7 @@ func (g *GameBoard) EatPowerup(playerIndex, PowerupIndex int) {
 		player.BombRange += powerup.Value
 	case "ExtraLife":
 		player.Lives += powerup.Value
-		g.SendMsgToChannel(struct {
+		g.emit(struct {
 			Type          string `json:"type"`
 			Player        int    `json:"player"`
 			NumberOfLives int    `json:"numberOfLives"`
@@ -146,12 +143,22 @@ func (g *GameBoard) EatPowerup(playerIndex, PowerupIndex int) {
 			Type:          "EatLifePowerup",
 			Player:        playerIndex,
 			NumberOfLives: player.Lives,
-		}, -1)
+		})
 	case "SpeedBoost":
 		if player.StepSize >= MaxSpeedPowerup {
 			return
 		}
 		player.StepSize += powerup.Value
+	case "Kick":
+		player.CanKick = true
+	case "Remote":
+		player.HasRemote = true
+	case "Pierce":
+		player.Pierce = true
+	case "BombPass":
+		player.BombPass = true
+	case "WallPass":
+		player.WallPass = true
 	}
 }
 
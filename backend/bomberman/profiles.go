@@ -0,0 +1,212 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Player profiles outlive the rooms: they are kept by player name in a JSON file and
+// updated at the end of every match. Bots have no profile.
+
+// OpenProfileStore loads the profiles from path. A missing file gives an empty store.
+func OpenProfileStore(path string) (*ProfileStore, error) {
+	s := &ProfileStore{path: path, profiles: make(map[string]*Profile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		s.profiles[p.Name] = p
+	}
+	return s, nil
+}
+
+// RecordMatch adds the results of one match to the profiles and saves the store.
+func (s *ProfileStore) RecordMatch(results []MatchRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, result := range results {
+		p := s.profiles[result.Name]
+		if p == nil {
+			p = &Profile{Name: result.Name}
+			s.profiles[result.Name] = p
+		}
+		p.GamesPlayed++
+		switch {
+		case result.Won:
+			p.Wins++
+		case !result.Draw:
+			p.Losses++
+		}
+		p.Kills += result.Kills
+		p.Deaths += result.Deaths
+		p.LastPlayed = now
+	}
+	return s.save()
+}
+
+// save writes every profile to a temporary file and moves it over the store, so a
+// crash never leaves a half written file. Must be called with s.mu held.
+func (s *ProfileStore) save() error {
+	profiles := make([]*Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Get returns a copy of a player's profile.
+func (s *ProfileStore) Get(name string) (Profile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[name]
+	if !ok {
+		return Profile{}, false
+	}
+	return *p, true
+}
+
+// Top returns the n best profiles: most wins first, then most kills, then fewest deaths.
+func (s *ProfileStore) Top(n int) []Profile {
+	s.mu.Lock()
+	profiles := make([]Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profiles = append(profiles, *p)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(profiles, func(i, j int) bool {
+		a, b := profiles[i], profiles[j]
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		if a.Deaths != b.Deaths {
+			return a.Deaths < b.Deaths
+		}
+		return a.Name < b.Name
+	})
+	if len(profiles) > n {
+		profiles = profiles[:n]
+	}
+	return profiles
+}
+
+// matchRecords lists the result of the match for every human player. Must be called with g.Mu held.
+func (g *GameBoard) matchRecords(winners []int) []MatchRecord {
+	won := make(map[int]bool)
+	for _, i := range winners {
+		won[i] = true
+	}
+	var records []MatchRecord
+	for i, player := range g.Players {
+		if player.IsBot {
+			continue
+		}
+		records = append(records, MatchRecord{
+			Name:   player.Name,
+			Won:    won[i],
+			Draw:   len(winners) == 0,
+			Kills:  player.Kills,
+			Deaths: player.Deaths,
+		})
+	}
+	return records
+}
+
+// LeaderboardHandler returns the best player profiles as JSON.
+// Query parameters: 'n', the number of profiles (default DefaultLeaderboardSize, at most MaxLeaderboardSize).
+func (m *RoomManager) LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	n := DefaultLeaderboardSize
+	if value := r.URL.Query().Get("n"); value != "" {
+		var err error
+		n, err = strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxLeaderboardSize {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "n must be a number from 1 to " + strconv.Itoa(MaxLeaderboardSize)})
+			return
+		}
+	}
+	if m.Profiles == nil {
+		json.NewEncoder(w).Encode([]Profile{})
+		return
+	}
+	json.NewEncoder(w).Encode(m.Profiles.Top(n))
+}
+
+// ProfileHandler returns the profile of the player given by the 'name' query parameter.
+func (m *RoomManager) ProfileHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Name parameter is required."})
+		return
+	}
+	var profile Profile
+	found := false
+	if m.Profiles != nil {
+		profile, found = m.Profiles.Get(name)
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "No profile for this name"})
+		return
+	}
+	json.NewEncoder(w).Encode(profile)
+}
+
+// saveMatch writes the match results to the profile store, logging any failure.
+func saveMatch(store *ProfileStore, records []MatchRecord) {
+	if store == nil || len(records) == 0 {
+		return
+	}
+	if err := store.RecordMatch(records); err != nil {
+		log.Printf("Could not save the match to the profiles: %v\n", err)
+	}
+}
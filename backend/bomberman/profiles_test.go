@@ -0,0 +1,97 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfilesSurviveReopening(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "profiles.json")
+	store, err := OpenProfileStore(path)
+	if err != nil {
+		t.Fatalf("opening a missing store: %v", err)
+	}
+	if _, ok := store.Get("ann"); ok {
+		t.Fatal("a new store should be empty")
+	}
+
+	matches := [][]MatchRecord{
+		{{Name: "ann", Won: true, Kills: 2}, {Name: "bob", Deaths: 1}},
+		{{Name: "ann", Draw: true, Deaths: 1}, {Name: "bob", Draw: true, Kills: 1, Deaths: 1}},
+	}
+	for _, records := range matches {
+		if err := store.RecordMatch(records); err != nil {
+			t.Fatalf("recording a match: %v", err)
+		}
+	}
+
+	reopened, err := OpenProfileStore(path)
+	if err != nil {
+		t.Fatalf("reopening the store: %v", err)
+	}
+	for _, want := range []Profile{
+		{Name: "ann", Wins: 1, Kills: 2, Deaths: 1, GamesPlayed: 2},
+		{Name: "bob", Losses: 1, Kills: 1, Deaths: 2, GamesPlayed: 2},
+	} {
+		got, ok := reopened.Get(want.Name)
+		got.LastPlayed = want.LastPlayed
+		if !ok || got != want {
+			t.Errorf("profile %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestLeaderboardOrder(t *testing.T) {
+	store, err := OpenProfileStore(filepath.Join(t.TempDir(), "profiles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.RecordMatch([]MatchRecord{
+		{Name: "ann", Kills: 3},
+		{Name: "bob", Won: true},
+		{Name: "cat", Kills: 3, Deaths: 1},
+		{Name: "dan", Kills: 1},
+	})
+
+	top := store.Top(3)
+	var names []string
+	for _, p := range top {
+		names = append(names, p.Name)
+	}
+	if len(names) != 3 || names[0] != "bob" || names[1] != "ann" || names[2] != "cat" {
+		t.Errorf("top 3 %v, want [bob ann cat]: wins, then kills, then fewest deaths", names)
+	}
+
+	m := NewRoomManager()
+	m.Profiles = store
+	for query, want := range map[string]int{"": 4, "n=2": 2, "n=0": -1, "n=x": -1, "n=1000": -1} {
+		w := httptest.NewRecorder()
+		m.LeaderboardHandler(w, httptest.NewRequest("GET", "/leaderboard?"+query, nil))
+		var profiles []Profile
+		err := json.NewDecoder(w.Body).Decode(&profiles)
+		if want == -1 {
+			if w.Code != 400 {
+				t.Errorf("leaderboard %q: status %d, want 400", query, w.Code)
+			}
+		} else if err != nil || len(profiles) != want {
+			t.Errorf("leaderboard %q: %d profiles (%v), want %d", query, len(profiles), err, want)
+		}
+	}
+}
+
+func TestMatchRecordsSkipBots(t *testing.T) {
+	g := newBombTestBoard([2]int{1, 1}, [2]int{9, 11}, [2]int{1, 11})
+	g.Players[2].IsBot = true
+	g.Players[0].Kills = 2
+
+	records := g.matchRecords([]int{0})
+	want := []MatchRecord{{Name: "a", Won: true, Kills: 2}, {Name: "b"}}
+	if len(records) != len(want) || records[0] != want[0] || records[1] != want[1] {
+		t.Errorf("records %+v, want %+v", records, want)
+	}
+	if records := g.matchRecords(nil); len(records) != 2 || !records[0].Draw || !records[1].Draw {
+		t.Errorf("records without winners %+v, want two draws", records)
+	}
+}
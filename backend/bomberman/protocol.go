@@ -0,0 +1,119 @@
+package bomberman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseClientMessage decodes a raw client frame into the typed message for its msgType.
+// Unknown fields, unknown types and invalid values are rejected with a ProtocolError.
+func ParseClientMessage(data []byte) (interface{}, *ProtocolError) {
+	var envelope struct {
+		MsgType string `json:"msgType"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, &ProtocolError{Code: ErrCodeBadJSON, Message: "message is not a valid JSON object"}
+	}
+
+	var msg interface{}
+	switch envelope.MsgType {
+	case "MS":
+		msg = &MoveStartMsg{}
+	case "ME":
+		msg = &MoveEndMsg{}
+	case "b":
+		msg = &BombMsg{}
+	case "detonate":
+		msg = &DetonateMsg{}
+	case "c":
+		msg = &ChatMsg{}
+	case "resync":
+		msg = &ResyncMsg{}
+	case "team":
+		msg = &TeamMsg{}
+	case "":
+		return nil, &ProtocolError{Code: ErrCodeMissingType, Message: "msgType is required"}
+	default:
+		return nil, &ProtocolError{Code: ErrCodeUnknownType, MsgType: envelope.MsgType, Message: fmt.Sprintf("unknown msgType %q", envelope.MsgType)}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(msg); err != nil {
+		return nil, &ProtocolError{Code: ErrCodeInvalidField, MsgType: envelope.MsgType, Message: err.Error()}
+	}
+
+	if perr := validateClientMessage(msg); perr != nil {
+		perr.MsgType = envelope.MsgType
+		return nil, perr
+	}
+	return msg, nil
+}
+
+func validateClientMessage(msg interface{}) *ProtocolError {
+	switch m := msg.(type) {
+	case *MoveStartMsg:
+		switch m.Direction {
+		case "u", "d", "l", "r":
+		default:
+			return &ProtocolError{Code: ErrCodeInvalidField, Message: "d must be one of u, d, l, r"}
+		}
+	case *TeamMsg:
+		if m.Team < 1 || m.Team > NumberOfTeams {
+			return &ProtocolError{Code: ErrCodeInvalidField, Message: fmt.Sprintf("team must be from 1 to %d", NumberOfTeams)}
+		}
+	case *ChatMsg:
+		if strings.TrimSpace(m.Content) == "" {
+			return &ProtocolError{Code: ErrCodeInvalidField, Message: "content must not be empty"}
+		}
+		if utf8.RuneCountInString(m.Content) > MaxChatLength {
+			return &ProtocolError{Code: ErrCodeInvalidField, Message: fmt.Sprintf("content must be at most %d characters", MaxChatLength)}
+		}
+	}
+	return nil
+}
+
+// handleClientMessage dispatches a parsed message to its handler.
+func (g *GameBoard) handleClientMessage(playerIndex int, msg interface{}) {
+	switch m := msg.(type) {
+	case *MoveStartMsg:
+		g.HandleMoveStartMessage(playerIndex, m.Direction)
+	case *MoveEndMsg:
+		g.HandleMoveEndMessage(playerIndex)
+	case *BombMsg:
+		g.HandleBombMessage(playerIndex)
+	case *DetonateMsg:
+		g.HandleDetonateMessage(playerIndex)
+	case *ChatMsg:
+		g.HandleChatMessage(playerIndex, m.Content)
+	case *TeamMsg:
+		g.HandleTeamMessage(playerIndex, m.Team)
+	case *ResyncMsg:
+		g.Mu.Lock()
+		snapshot := g.Snapshot()
+		g.Mu.Unlock()
+		g.SendMsgToPlayer(snapshot, playerIndex)
+	}
+}
+
+// SendError tells a single player why their message was rejected.
+func (g *GameBoard) SendError(playerIndex int, perr *ProtocolError) {
+	g.SendMsgToPlayer(ErrorMsg{
+		Type:    "Error",
+		Code:    perr.Code,
+		MsgType: perr.MsgType,
+		Message: perr.Message,
+	}, playerIndex)
+}
+
+// validPlayer reports whether playerIndex points at a player of this board. Must be called with g.Mu held.
+func (g *GameBoard) validPlayer(playerIndex int) bool {
+	return playerIndex >= 0 && playerIndex < len(g.Players)
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Code + ": " + e.Message
+}
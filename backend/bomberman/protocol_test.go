@@ -0,0 +1,57 @@
+package bomberman
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClientMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantCode string // empty when the message is valid
+	}{
+		{"move", `{"msgType":"MS","d":"u"}`, ""},
+		{"move end", `{"msgType":"ME"}`, ""},
+		{"bomb", `{"msgType":"b"}`, ""},
+		{"detonate", `{"msgType":"detonate"}`, ""},
+		{"chat", `{"msgType":"c","content":"hi"}`, ""},
+		{"chat at the limit", `{"msgType":"c","content":"` + strings.Repeat("é", MaxChatLength) + `"}`, ""},
+		{"resync", `{"msgType":"resync"}`, ""},
+		{"team", `{"msgType":"team","team":2}`, ""},
+		{"not json", `hello`, ErrCodeBadJSON},
+		{"json array", `[1,2]`, ErrCodeBadJSON},
+		{"type not a string", `{"msgType":1}`, ErrCodeBadJSON},
+		{"missing type", `{"d":"u"}`, ErrCodeMissingType},
+		{"unknown type", `{"msgType":"fly"}`, ErrCodeUnknownType},
+		{"unknown field", `{"msgType":"b","row":3}`, ErrCodeInvalidField},
+		{"wrong field type", `{"msgType":"team","team":"red"}`, ErrCodeInvalidField},
+		{"bad direction", `{"msgType":"MS","d":"x"}`, ErrCodeInvalidField},
+		{"missing direction", `{"msgType":"MS"}`, ErrCodeInvalidField},
+		{"empty chat", `{"msgType":"c","content":"  "}`, ErrCodeInvalidField},
+		{"chat too long", `{"msgType":"c","content":"` + strings.Repeat("a", MaxChatLength+1) + `"}`, ErrCodeInvalidField},
+		{"team too low", `{"msgType":"team","team":0}`, ErrCodeInvalidField},
+		{"team too high", `{"msgType":"team","team":3}`, ErrCodeInvalidField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, perr := ParseClientMessage([]byte(tt.data))
+			if tt.wantCode == "" {
+				if perr != nil {
+					t.Fatalf("unexpected error: %v", perr)
+				}
+				if msg == nil {
+					t.Fatal("no message returned")
+				}
+				return
+			}
+			if perr == nil {
+				t.Fatalf("accepted %T, want error %s", msg, tt.wantCode)
+			}
+			if perr.Code != tt.wantCode {
+				t.Fatalf("error code %s (%s), want %s", perr.Code, perr.Message, tt.wantCode)
+			}
+		})
+	}
+}
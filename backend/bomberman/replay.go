@@ -0,0 +1,266 @@
+package bomberman
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var replayIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// NewRecorder creates a replay file for the given room in dir, deleting the oldest
+// replays so the directory keeps at most MaxReplays of them.
+func NewRecorder(dir, roomID string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	pruneReplays(dir, MaxReplays-1)
+	id := fmt.Sprintf("%s-%d", roomID, time.Now().Unix())
+	file, err := os.Create(filepath.Join(dir, id+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		ID:      id,
+		file:    file,
+		encoder: json.NewEncoder(file),
+		start:   time.Now(),
+	}, nil
+}
+
+// Record appends one event to the replay. data must be a JSON encoded message.
+func (r *Recorder) Record(kind string, playerIndex int, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	event := ReplayEvent{
+		Time:        time.Since(r.start).Milliseconds(),
+		Kind:        kind,
+		PlayerIndex: playerIndex,
+		Msg:         json.RawMessage(data),
+	}
+	if err := r.encoder.Encode(event); err != nil {
+		log.Printf("Error recording replay %s: %v\n", r.ID, err)
+	}
+}
+
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	if err := r.file.Close(); err != nil {
+		log.Printf("Error closing replay %s: %v\n", r.ID, err)
+	}
+	r.file = nil
+}
+
+// pruneReplays deletes the oldest replay files in dir until at most keep are left.
+func pruneReplays(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("Could not list replays in %s: %v\n", dir, err)
+		return
+	}
+	type replayFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []replayFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, replayFile{entry.Name(), info.ModTime()})
+	}
+	if len(files) <= keep {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files[:len(files)-keep] {
+		if err := os.Remove(filepath.Join(dir, file.name)); err != nil {
+			log.Printf("Could not delete replay %s: %v\n", file.name, err)
+		}
+	}
+}
+
+// startRecording opens a replay for the match and stores the board as it is now as the first event.
+// Recording is skipped when the board has no ReplayDir. Must be called with g.Mu held.
+func (g *GameBoard) startRecording() {
+	if g.ReplayDir == "" || g.recorder != nil {
+		return
+	}
+	recorder, err := NewRecorder(g.ReplayDir, g.ID)
+	if err != nil {
+		log.Printf("Room %s: could not start replay: %v\n", g.ID, err)
+		return
+	}
+	g.recorder = recorder
+	if data, err := json.Marshal(g.Snapshot()); err == nil {
+		recorder.Record("out", -1, data)
+	}
+	log.Printf("Room %s: recording replay %s\n", g.ID, recorder.ID)
+}
+
+// stopRecording closes the match's replay, if any. Must be called with g.Mu held.
+func (g *GameBoard) stopRecording() {
+	if g.recorder == nil {
+		return
+	}
+	g.recorder.Close()
+	g.recorder = nil
+}
+
+// ListReplays returns the IDs of the recorded replays in dir, newest first.
+func ListReplays(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".jsonl"))
+		}
+	}
+	// IDs end with the unix time the recording started, after a random room ID
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := replayStartTime(ids[i]), replayStartTime(ids[j])
+		if a != b {
+			return a > b
+		}
+		return ids[i] > ids[j]
+	})
+	return ids, nil
+}
+
+// replayStartTime returns the unix time at the end of a replay ID, 0 when there is none.
+func replayStartTime(id string) int64 {
+	i := strings.LastIndex(id, "-")
+	if i == -1 {
+		return 0
+	}
+	unix, err := strconv.ParseInt(id[i+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return unix
+}
+
+// ReplaysHandler responds with the list of recorded replays as JSON.
+func (m *RoomManager) ReplaysHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if m.ReplayDir == "" {
+		json.NewEncoder(w).Encode([]string{})
+		return
+	}
+	ids, err := ListReplays(m.ReplayDir)
+	if err != nil {
+		log.Printf("ReplaysHandler: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Could not list replays"})
+		return
+	}
+	json.NewEncoder(w).Encode(ids)
+}
+
+// ReplayHandler streams a recorded match over a websocket, like a spectator connection.
+// Query parameters: 'id' of the replay and 'speed' (1, 2 or 4, default 1).
+func (m *RoomManager) ReplayHandler(w http.ResponseWriter, r *http.Request) {
+	if m.ReplayDir == "" {
+		http.Error(w, "Replays are disabled", http.StatusNotFound)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if !replayIDPattern.MatchString(id) {
+		http.Error(w, "Invalid replay id", http.StatusBadRequest)
+		return
+	}
+	speed := 1
+	if s := r.URL.Query().Get("speed"); s != "" {
+		speed, _ = strconv.Atoi(s)
+		if speed != 1 && speed != 2 && speed != 4 {
+			http.Error(w, "Speed must be 1, 2 or 4", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, err := os.Open(filepath.Join(m.ReplayDir, id+".jsonl"))
+	if err != nil {
+		http.Error(w, "Replay not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	conn, err := Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("Upgrade error:", err)
+		return
+	}
+	defer conn.Close()
+
+	// Stop playback as soon as the viewer goes away
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	log.Printf("Playing replay %s at %dx\n", id, speed)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	var lastTime int64
+	for scanner.Scan() {
+		var event ReplayEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			log.Printf("Replay %s: skipping bad line: %v\n", id, err)
+			continue
+		}
+		if event.Kind != "out" {
+			continue
+		}
+
+		wait := time.Duration(event.Time-lastTime) * time.Millisecond / time.Duration(speed)
+		lastTime = event.Time
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, event.Msg); err != nil {
+			return
+		}
+	}
+	conn.WriteJSON(map[string]string{"type": "ReplayEnd", "id": id})
+}
@@ -0,0 +1,76 @@
+package bomberman
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewRecorderPrunesOldestReplays(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < MaxReplays+5; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("old-%03d.jsonl", i))
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := base.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder, err := NewRecorder(dir, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Close()
+
+	ids, err := ListReplays(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != MaxReplays {
+		t.Fatalf("%d replays kept, want %d", len(ids), MaxReplays)
+	}
+	for i := 0; i < 6; i++ {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("old-%03d.jsonl", i))); !os.IsNotExist(err) {
+			t.Errorf("replay %d should have been deleted", i)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, recorder.ID+".jsonl")); err != nil {
+		t.Errorf("new replay missing: %v", err)
+	}
+}
+
+func TestReplayHandlerWithoutReplayDir(t *testing.T) {
+	m := NewRoomManager()
+	m.ReplayDir = ""
+	w := httptest.NewRecorder()
+	m.ReplayHandler(w, httptest.NewRequest("GET", "/replay?id=room-1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404", w.Code)
+	}
+}
+
+func TestListReplaysNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"zz-1000", "aa-3000", "mm-2000", "aa-1000"} {
+		if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := ListReplays(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"aa-3000", "mm-2000", "zz-1000", "aa-1000"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ListReplays = %v, want %v", ids, want)
+	}
+}
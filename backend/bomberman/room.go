@@ -0,0 +1,427 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+func NewRoomManager() *RoomManager {
+	m := &RoomManager{
+		Rooms:       make(map[string]*GameBoard),
+		Maps:        make(map[string]*GameMap),
+		Rules:       DefaultRules(),
+		TickRate:    DefaultTickRate,
+		Transcripts: &TranscriptStore{},
+		Moderator:   NewChatModerator(nil),
+	}
+	go m.cleanupLoop()
+	return m
+}
+
+// CreateRoom creates a new lobby with its own board, broadcaster and game loop.
+func (m *RoomManager) CreateRoom(opts RoomOptions) (*GameBoard, error) {
+	var gameMap *GameMap
+	if opts.Map != "" {
+		gameMap = m.Maps[opts.Map]
+		if gameMap == nil {
+			return nil, fmt.Errorf("unknown map %q", opts.Map)
+		}
+		if opts.Rows != 0 || opts.Columns != 0 {
+			return nil, fmt.Errorf("map %q has its own size", opts.Map)
+		}
+	}
+
+	rules := m.Rules
+	if err := rules.Apply(opts.Rules); err != nil {
+		return nil, err
+	}
+
+	botLevel := opts.BotLevel
+	if botLevel == "" {
+		botLevel = DefaultBotLevel
+	}
+	if _, ok := BotLevels[botLevel]; !ok {
+		return nil, fmt.Errorf("unknown bot level %q", botLevel)
+	}
+	if opts.Bots < 0 || opts.Bots >= MaxNumberOfPlayers {
+		return nil, fmt.Errorf("bots must be from 0 to %d", MaxNumberOfPlayers-1)
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeFreeForAll
+	}
+	if mode != ModeFreeForAll && mode != ModeTeams {
+		return nil, fmt.Errorf("mode must be %q or %q", ModeFreeForAll, ModeTeams)
+	}
+
+	if m.roomCount() >= MaxRooms {
+		return nil, ErrTooManyRooms
+	}
+
+	id := strings.Split(uuid.New().String(), "-")[0]
+	g := InitGame(id, opts.Seed)
+	g.Rules = rules
+	if m.TickRate > 0 {
+		g.TickRate = m.TickRate // Movement divides by it, so a room never runs at a rate of 0
+	}
+	g.ReplayDir = m.ReplayDir
+	g.Profiles = m.Profiles
+	g.Transcripts = m.Transcripts
+	g.Moderator = m.Moderator
+	g.Map = gameMap
+	g.MapName = opts.Map
+	g.Mode = mode
+	g.FriendlyFire = opts.FriendlyFire
+	if opts.Rows != 0 {
+		g.SetSize(opts.Rows, opts.Columns)
+	}
+	g.BuildBoard()
+	g.Mu.Lock()
+	for i := 0; i < opts.Bots; i++ {
+		g.AddBot(botLevel)
+	}
+	g.Mu.Unlock()
+	if opts.BotFill {
+		g.BotFill = botLevel
+	}
+	g.OnFinished = func(g *GameBoard) {
+		m.RemoveRoom(g.ID)
+	}
+	m.Mu.Lock()
+	if len(m.Rooms) >= MaxRooms {
+		// Another request took the last place while this board was built
+		m.Mu.Unlock()
+		return nil, ErrTooManyRooms
+	}
+	m.Rooms[id] = g
+	m.Mu.Unlock()
+	g.Start()
+
+	log.Printf("Room %s created\n", id)
+	return g, nil
+}
+
+func (m *RoomManager) roomCount() int {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	return len(m.Rooms)
+}
+
+func (m *RoomManager) GetRoom(id string) *GameBoard {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	return m.Rooms[id]
+}
+
+// FindOpenRoom returns the oldest room that still accepts players, or nil if none does.
+func (m *RoomManager) FindOpenRoom() *GameBoard {
+	for _, info := range m.ListRooms() {
+		g := m.GetRoom(info.ID)
+		if g == nil {
+			continue
+		}
+		g.Mu.Lock()
+		open := g.CanCreateNewPlayer()
+		g.Mu.Unlock()
+		if open {
+			return g
+		}
+	}
+	return nil
+}
+
+// FindRoomByPlayer returns the room the player with the given UUID belongs to, or nil.
+func (m *RoomManager) FindRoomByPlayer(UUID string) *GameBoard {
+	m.Mu.Lock()
+	rooms := make([]*GameBoard, 0, len(m.Rooms))
+	for _, g := range m.Rooms {
+		rooms = append(rooms, g)
+	}
+	m.Mu.Unlock()
+
+	for _, g := range rooms {
+		g.Mu.Lock()
+		playerIndex := g.GetPlayerByUUID(UUID)
+		g.Mu.Unlock()
+		if playerIndex != -1 {
+			return g
+		}
+	}
+	return nil
+}
+
+func (m *RoomManager) RemoveRoom(id string) {
+	m.Mu.Lock()
+	g, ok := m.Rooms[id]
+	delete(m.Rooms, id)
+	m.Mu.Unlock()
+
+	if ok {
+		g.Close()
+		log.Printf("Room %s removed\n", id)
+	}
+}
+
+// ListRooms returns a summary of every room, oldest first.
+func (m *RoomManager) ListRooms() []RoomInfo {
+	m.Mu.Lock()
+	rooms := make([]*GameBoard, 0, len(m.Rooms))
+	for _, g := range m.Rooms {
+		rooms = append(rooms, g)
+	}
+	m.Mu.Unlock()
+
+	infos := make([]RoomInfo, 0, len(rooms))
+	for _, g := range rooms {
+		g.Mu.Lock()
+		infos = append(infos, RoomInfo{
+			ID:              g.ID,
+			State:           g.GameState,
+			NumberOfPlayers: g.NumberOfPlayers,
+			MaxPlayers:      MaxNumberOfPlayers,
+			Spectators:      len(g.SpectatorConnections),
+			Seed:            g.Seed,
+			Map:             g.MapName,
+			Rows:            g.Rows,
+			Columns:         g.Columns,
+			Bots:            g.NumberOfBots(),
+			Mode:            g.Mode,
+			CreatedAt:       g.CreatedAt,
+		})
+		g.Mu.Unlock()
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// cleanupLoop removes lobbies that nobody joined within RoomIdleTimeout.
+func (m *RoomManager) cleanupLoop() {
+	ticker := time.NewTicker(RoomCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, info := range m.ListRooms() {
+			if info.State == "lobby" && info.NumberOfPlayers == info.Bots && time.Since(info.CreatedAt) > RoomIdleTimeout {
+				log.Printf("Room %s is idle, cleaning up\n", info.ID)
+				m.RemoveRoom(info.ID)
+			}
+		}
+	}
+}
+
+// ParseRoomOptions reads the settings of a new room from query parameters: 'seed', 'map',
+// 'rows' and 'cols' for the size of a random board, 'bots', 'fill=true' and 'botLevel'
+// for bot players, 'mode=teams' and 'friendlyFire=true' for a team match, and any rule name (see RuleNames).
+// Rule values and the bot settings are checked when the room is created.
+func ParseRoomOptions(query url.Values) (RoomOptions, error) {
+	var opts RoomOptions
+	opts.Map = query.Get("map")
+	opts.BotLevel = query.Get("botLevel")
+	opts.BotFill = query.Get("fill") == "true"
+	opts.Mode = query.Get("mode")
+	opts.FriendlyFire = query.Get("friendlyFire") == "true"
+	if bots := query.Get("bots"); bots != "" {
+		value, err := strconv.Atoi(bots)
+		if err != nil {
+			return opts, fmt.Errorf("invalid bots %q", bots)
+		}
+		opts.Bots = value
+	}
+	for _, rule := range RuleNames {
+		if value := query.Get(rule[0]); value != "" {
+			if opts.Rules == nil {
+				opts.Rules = make(RuleOverrides)
+			}
+			opts.Rules[rule[0]] = value
+		}
+	}
+	if seed := query.Get("seed"); seed != "" {
+		value, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid seed %q", seed)
+		}
+		opts.Seed = value
+	}
+
+	rows, cols := query.Get("rows"), query.Get("cols")
+	if rows == "" && cols == "" {
+		return opts, nil
+	}
+	var err error
+	if opts.Rows, err = parseBoardSize("rows", rows, DefaultNumberOfRows); err != nil {
+		return opts, err
+	}
+	if opts.Columns, err = parseBoardSize("cols", cols, DefaultNumberOfColumns); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// parseBoardSize reads one board dimension, falling back to def when it is empty.
+func parseBoardSize(name, value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number from %d to %d", name, MinBoardSize, MaxBoardSize)
+	}
+	return size, CheckBoardSize(name, size)
+}
+
+// CheckBoardSize makes sure a board dimension is from MinBoardSize to MaxBoardSize.
+func CheckBoardSize(name string, size int) error {
+	if size < MinBoardSize || size > MaxBoardSize {
+		return fmt.Errorf("%s must be a number from %d to %d", name, MinBoardSize, MaxBoardSize)
+	}
+	return nil
+}
+
+// CheckNameHandler picks the room for the request and lets it register the player.
+// Query parameters: 'name', and optionally 'room' to join a specific room or 'create=true' to open a new one
+// (see ParseRoomOptions for the settings of a new room).
+// Without either, the player is placed in the first room that is still in the lobby.
+func (m *RoomManager) CheckNameHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var g *GameBoard
+	roomID := r.URL.Query().Get("room")
+	switch {
+	case r.URL.Query().Get("create") == "true":
+		opts, err := ParseRoomOptions(r.URL.Query())
+		if err != nil {
+			log.Printf("CheckNameHandler: Invalid room options: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"reason": err.Error()})
+			return
+		}
+		m.createRoomFor(w, r, opts)
+		return
+	case roomID != "":
+		g = m.GetRoom(roomID)
+	default:
+		if g = m.FindOpenRoom(); g == nil {
+			m.createRoomFor(w, r, RoomOptions{})
+			return
+		}
+	}
+
+	if g == nil {
+		log.Printf("CheckNameHandler: Room '%s' not found.", roomID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"reason": "Room not found"})
+		return
+	}
+	g.CheckNameHandler(w, r)
+}
+
+// createRoomFor opens a room and registers the requesting player in it. The room is
+// removed again when the player's name is refused, so a failed request leaves nothing behind.
+func (m *RoomManager) createRoomFor(w http.ResponseWriter, r *http.Request, opts RoomOptions) {
+	g, err := m.CreateRoom(opts)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrTooManyRooms) {
+			status = http.StatusServiceUnavailable
+		}
+		log.Printf("CheckNameHandler: Cannot create a room: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"reason": err.Error()})
+		return
+	}
+	g.CheckNameHandler(w, r)
+	if !g.hasHumanPlayer() {
+		m.RemoveRoom(g.ID)
+	}
+}
+
+// HandleWSConnections routes the websocket to the room given by the 'room' query parameter,
+// or to the room the player's UUID was registered in.
+func (m *RoomManager) HandleWSConnections(w http.ResponseWriter, r *http.Request) {
+	var g *GameBoard
+	if roomID := r.URL.Query().Get("room"); roomID != "" {
+		g = m.GetRoom(roomID)
+	} else {
+		g = m.FindRoomByPlayer(r.URL.Query().Get("UUID"))
+	}
+
+	if g == nil {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("Upgrade error:", err)
+			return
+		}
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(1008, "Room not found"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	g.HandleWSConnections(w, r)
+}
+
+// SpectateHandler attaches a spectator to the room given by the 'room' query parameter.
+func (m *RoomManager) SpectateHandler(w http.ResponseWriter, r *http.Request) {
+	g := m.GetRoom(r.URL.Query().Get("room"))
+	if g == nil {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("Upgrade error:", err)
+			return
+		}
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(1008, "Room not found"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	g.HandleSpectatorConnection(w, r)
+}
+
+// ListRoomsHandler responds with the list of rooms as JSON.
+func (m *RoomManager) ListRoomsHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.ListRooms()); err != nil {
+		log.Printf("ListRoomsHandler: Error encoding JSON response: %v", err)
+	}
+}
+
+// MapsHandler responds with the names of the custom maps as JSON.
+func (m *RoomManager) MapsHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.MapNames()); err != nil {
+		log.Printf("MapsHandler: Error encoding JSON response: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package bomberman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func checkName(m *RoomManager, query string) int {
+	w := httptest.NewRecorder()
+	m.CheckNameHandler(w, httptest.NewRequest("GET", "/checkName?"+query, nil))
+	return w.Code
+}
+
+func TestCreateRoomWithRefusedNameLeavesNoRoom(t *testing.T) {
+	m := NewRoomManager()
+	for _, query := range []string{"create=true", "create=true&name=averyveryverylongname", "name=averyveryverylongname", "create=true&name=me&bots=1"} {
+		code := checkName(m, query)
+		if query == "create=true&name=me&bots=1" {
+			if code != http.StatusOK {
+				t.Fatalf("%s: status %d, want 200", query, code)
+			}
+			continue
+		}
+		if code == http.StatusOK {
+			t.Fatalf("%s: status 200, want the name refused", query)
+		}
+		if n := m.roomCount(); n != 0 {
+			t.Fatalf("%s: %d rooms left after a refused name", query, n)
+		}
+	}
+	if n := m.roomCount(); n != 1 {
+		t.Fatalf("%d rooms, want the one with an accepted name", n)
+	}
+	for _, info := range m.ListRooms() {
+		m.RemoveRoom(info.ID)
+	}
+}
+
+func TestCreateRoomLimit(t *testing.T) {
+	m := NewRoomManager()
+	defer func() {
+		for _, info := range m.ListRooms() {
+			m.RemoveRoom(info.ID)
+		}
+	}()
+	for i := 0; i < MaxRooms; i++ {
+		if _, err := m.CreateRoom(RoomOptions{}); err != nil {
+			t.Fatalf("room %d: %v", i, err)
+		}
+	}
+	if code := checkName(m, "create=true&name=late"); code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503 once %d rooms are open", code, MaxRooms)
+	}
+	if n := m.roomCount(); n != MaxRooms {
+		t.Fatalf("%d rooms, want %d", n, MaxRooms)
+	}
+}
@@ -0,0 +1,146 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultRules returns the classic rules of the game.
+func DefaultRules() Rules {
+	return Rules{
+		Lives:               3,
+		StartingBombs:       3,
+		BombRange:           2,
+		BombDelay:           3 * time.Second,
+		StepSize:            5,
+		LobbyCountdown:      20,
+		GameCountdown:       10,
+		PowerupDropChance:   0.3,
+		Invulnerability:     1 * time.Second,
+		SuddenDeath:         2 * time.Minute,
+		SuddenDeathInterval: 500 * time.Millisecond,
+		KillPoints:          100,
+		SelfKillPoints:      -50,
+		WallPoints:          10,
+	}
+}
+
+// Set changes one rule by name, parsing and range checking the value.
+func (r *Rules) Set(name, value string) error {
+	var err error
+	switch name {
+	case "lives":
+		r.Lives, err = parseRuleInt(value, 1, 10)
+	case "bombs":
+		r.StartingBombs, err = parseRuleInt(value, 1, MaxBombsPowerup)
+	case "range":
+		r.BombRange, err = parseRuleInt(value, 1, MaxBombRangePowerup)
+	case "bombDelay":
+		r.BombDelay, err = parseRuleDuration(value, 500*time.Millisecond, 10*time.Second)
+	case "step":
+		r.StepSize, err = parseRuleInt(value, 1, MaxSpeedPowerup)
+	case "lobbyCountdown":
+		r.LobbyCountdown, err = parseRuleInt(value, 0, 60)
+	case "gameCountdown":
+		r.GameCountdown, err = parseRuleInt(value, 0, 60)
+	case "dropChance":
+		r.PowerupDropChance, err = strconv.ParseFloat(value, 64)
+		if err == nil && (r.PowerupDropChance < 0 || r.PowerupDropChance > 1) {
+			err = fmt.Errorf("must be between 0 and 1")
+		}
+	case "invulnerability":
+		r.Invulnerability, err = parseRuleDuration(value, 0, 10*time.Second)
+	case "suddenDeath":
+		r.SuddenDeath, err = parseRuleDuration(value, 0, 30*time.Minute)
+	case "suddenDeathInterval":
+		r.SuddenDeathInterval, err = parseRuleDuration(value, 50*time.Millisecond, 10*time.Second)
+	case "killPoints":
+		r.KillPoints, err = parseRuleInt(value, -1000, 1000)
+	case "selfKillPoints":
+		r.SelfKillPoints, err = parseRuleInt(value, -1000, 1000)
+	case "wallPoints":
+		r.WallPoints, err = parseRuleInt(value, -1000, 1000)
+	default:
+		return fmt.Errorf("unknown rule %q", name)
+	}
+	if err != nil {
+		return fmt.Errorf("rule %s: %v", name, err)
+	}
+	return nil
+}
+
+// Apply sets every rule in overrides.
+func (r *Rules) Apply(overrides RuleOverrides) error {
+	for name, value := range overrides {
+		if err := r.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseRuleInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("must be between %d and %d", min, max)
+	}
+	return n, nil
+}
+
+func parseRuleDuration(value string, min, max time.Duration) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	if d < min || d > max {
+		return 0, fmt.Errorf("must be between %v and %v", min, max)
+	}
+	return d, nil
+}
+
+// LoadRules reads the rules from a JSON file of rule names to values, for example
+// {"lives": 5, "bombDelay": "2s"}. Rules missing from the file keep their default.
+// An empty path gives the default rules.
+func LoadRules(path string) (Rules, error) {
+	rules := DefaultRules()
+	if path == "" {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return rules, fmt.Errorf("%s: %v", path, err)
+	}
+	overrides := make(RuleOverrides)
+	for name, value := range values {
+		overrides[name] = fmt.Sprint(value)
+	}
+	if err := rules.Apply(overrides); err != nil {
+		return rules, fmt.Errorf("%s: %v", path, err)
+	}
+	return rules, nil
+}
+
+// RuleFlags registers a command line flag for every rule. The returned overrides
+// are filled in by fs.Parse with the flags that were given.
+func RuleFlags(fs *flag.FlagSet) RuleOverrides {
+	overrides := make(RuleOverrides)
+	for _, rule := range RuleNames {
+		name := rule[0]
+		fs.Func(name, rule[1], func(value string) error {
+			overrides[name] = value
+			return nil
+		})
+	}
+	return overrides
+}
@@ -0,0 +1,96 @@
+package bomberman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRulesSet(t *testing.T) {
+	tests := []struct {
+		name, value string
+		ok          bool
+	}{
+		{"lives", "1", true},
+		{"lives", "10", true},
+		{"lives", "0", false},
+		{"lives", "11", false},
+		{"lives", "three", false},
+		{"bombs", "0", false},
+		{"range", "1", true},
+		{"bombDelay", "2s", true},
+		{"bombDelay", "100ms", false},
+		{"bombDelay", "11s", false},
+		{"bombDelay", "3", false},
+		{"step", "0", false},
+		{"lobbyCountdown", "0", true},
+		{"lobbyCountdown", "61", false},
+		{"gameCountdown", "-1", false},
+		{"dropChance", "0", true},
+		{"dropChance", "1", true},
+		{"dropChance", "1.5", false},
+		{"dropChance", "-0.1", false},
+		{"dropChance", "often", false},
+		{"invulnerability", "0s", true},
+		{"suddenDeath", "31m", false},
+		{"suddenDeathInterval", "10ms", false},
+		{"killPoints", "-1000", true},
+		{"selfKillPoints", "1001", false},
+		{"wallPoints", "5", true},
+		{"gravity", "1", false},
+	}
+
+	for _, tt := range tests {
+		rules := DefaultRules()
+		err := rules.Set(tt.name, tt.value)
+		if tt.ok && err != nil {
+			t.Errorf("Set(%s, %s): unexpected error %v", tt.name, tt.value, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("Set(%s, %s): accepted, want an error", tt.name, tt.value)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rules, err := LoadRules("")
+	if err != nil || rules != DefaultRules() {
+		t.Fatalf("LoadRules(\"\") = %+v, %v, want the default rules", rules, err)
+	}
+
+	rules, err = LoadRules(write("ok.json", `{"lives": 5, "bombDelay": "2s", "dropChance": 0.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Lives != 5 || rules.BombDelay != 2*time.Second || rules.PowerupDropChance != 0.5 {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+	if rules.StartingBombs != DefaultRules().StartingBombs {
+		t.Fatalf("rules missing from the file should keep their default")
+	}
+
+	for _, tt := range []struct{ file, content, wantErr string }{
+		{"bad.json", `{"lives": `, "bad.json"},
+		{"range.json", `{"lives": 50}`, "rule lives"},
+		{"unknown.json", `{"gravity": 1}`, "unknown rule"},
+		{"type.json", `{"bombDelay": 2}`, "not a duration"},
+	} {
+		if _, err := LoadRules(write(tt.file, tt.content)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error %v, want one containing %q", tt.file, err, tt.wantErr)
+		}
+	}
+	if _, err := LoadRules(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file: want an error")
+	}
+}
@@ -0,0 +1,79 @@
+package bomberman
+
+import (
+	"fmt"
+	"time"
+)
+
+func NewMatchStats() MatchStats {
+	return MatchStats{
+		LivesLost:    make(map[string]int),
+		Eliminations: make(map[string]int),
+		Powerups:     make(map[string]int),
+	}
+}
+
+// SimulateMatch plays one match between bots on a simulated clock, without any
+// connections, as fast as the machine allows.
+func SimulateMatch(opts SimOptions) (MatchResult, error) {
+	if len(opts.BotLevels) < MinNumberOfPlayers || len(opts.BotLevels) > MaxNumberOfPlayers {
+		return MatchResult{}, fmt.Errorf("a match needs %d to %d bots", MinNumberOfPlayers, MaxNumberOfPlayers)
+	}
+	if opts.Rows != 0 || opts.Columns != 0 {
+		if err := CheckBoardSize("rows", opts.Rows); err != nil {
+			return MatchResult{}, err
+		}
+		if err := CheckBoardSize("columns", opts.Columns); err != nil {
+			return MatchResult{}, err
+		}
+	}
+	tickRate := opts.TickRate
+	if tickRate <= 0 {
+		tickRate = DefaultTickRate
+	}
+
+	clock := time.Unix(0, 0)
+	g := InitGame("sim", opts.Seed)
+	g.Clock = func() time.Time { return clock }
+	g.TickRate = tickRate
+	g.Rules = opts.Rules
+	g.Map = opts.Map
+	if opts.Rows != 0 {
+		g.SetSize(opts.Rows, opts.Columns)
+	}
+	g.OnFinished = func(*GameBoard) {}
+	g.BuildBoard()
+	for _, level := range opts.BotLevels {
+		if err := g.AddBot(level); err != nil {
+			return MatchResult{}, err
+		}
+	}
+	g.IsStarted = true
+	g.GameState = "gameStarted"
+	g.StartedAt = g.now()
+
+	maxTicks := int(opts.MaxDuration.Seconds() * float64(tickRate))
+	for g.IsStarted && (maxTicks <= 0 || g.TickCount < maxTicks) {
+		clock = clock.Add(time.Second / time.Duration(tickRate))
+		g.Tick()
+	}
+	g.Close()
+
+	result := MatchResult{
+		Seed:     g.Seed,
+		Winner:   -1,
+		Spawns:   g.Spawns[:len(g.Players)],
+		Ticks:    g.TickCount,
+		Duration: float64(g.TickCount) / float64(tickRate),
+		TimedOut: g.IsStarted,
+		Stats:    g.Stats,
+	}
+	if !result.TimedOut {
+		for i, player := range g.Players {
+			if player.Lives > 0 {
+				result.Winner = i
+			}
+		}
+	}
+	return result, nil
+}
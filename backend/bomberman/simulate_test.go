@@ -0,0 +1,12 @@
+package bomberman
+
+import "testing"
+
+func TestSimulateMatchRejectsBoardSizesOutOfBounds(t *testing.T) {
+	for _, size := range [][2]int{{3, 3}, {MinBoardSize - 1, 11}, {11, MaxBoardSize + 1}, {11, 0}} {
+		opts := SimOptions{Seed: 1, Rows: size[0], Columns: size[1], Rules: DefaultRules(), BotLevels: []string{"easy", "easy"}}
+		if _, err := SimulateMatch(opts); err == nil {
+			t.Errorf("%dx%d board accepted", size[0], size[1])
+		}
+	}
+}
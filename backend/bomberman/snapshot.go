@@ -0,0 +1,64 @@
+package bomberman
+
+import "slices"
+
+// Snapshot captures everything a client needs to rebuild the board from scratch.
+// Hidden powerups are left out so they stay a surprise. Must be called with g.Mu held.
+func (g *GameBoard) Snapshot() SnapshotMsg {
+	snapshot := SnapshotMsg{
+		Type:            "Snapshot",
+		Seq:             g.LastSeq(),
+		State:           g.GameState,
+		Seed:            g.Seed,
+		Players:         append([]Player(nil), g.Players...),
+		NumberOfPlayers: g.NumberOfPlayers,
+		Panel:           copyPanel(g.Panel),
+		Bombs:           append([]Bomb(nil), g.Bombs...),
+		Powerups:        []Powerup{},
+	}
+	for _, powerup := range g.Powerups {
+		if !powerup.IsHidden {
+			snapshot.Powerups = append(snapshot.Powerups, powerup)
+		}
+	}
+	return snapshot
+}
+
+// emitDelta adds a Delta to the current tick's batch when the board changed since
+// the previous one. Must be called with g.Mu held.
+func (g *GameBoard) emitDelta() {
+	current := g.Snapshot()
+	base := g.deltaBase
+	g.deltaBase = &current
+	if base == nil {
+		return
+	}
+
+	delta := DeltaMsg{
+		Type: "Delta",
+		Tick: g.TickCount,
+	}
+	for row := range current.Panel {
+		for col := range current.Panel[row] {
+			if current.Panel[row][col] != base.Panel[row][col] {
+				delta.Cells = append(delta.Cells, CellChange{Row: row, Col: col, Value: current.Panel[row][col]})
+			}
+		}
+	}
+	for i, player := range current.Players {
+		if i >= len(base.Players) || player != base.Players[i] {
+			delta.Players = append(delta.Players, player)
+		}
+	}
+	if !slices.Equal(current.Bombs, base.Bombs) {
+		delta.Bombs = append([]Bomb{}, current.Bombs...)
+	}
+	if !slices.Equal(current.Powerups, base.Powerups) {
+		delta.Powerups = current.Powerups
+	}
+
+	if delta.Cells == nil && delta.Players == nil && delta.Bombs == nil && delta.Powerups == nil {
+		return
+	}
+	g.emit(delta)
+}
@@ -0,0 +1,52 @@
+package bomberman
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmitDelta(t *testing.T) {
+	g := newBombTestBoard([2]int{1, 1}, [2]int{9, 11})
+
+	g.emitDelta()
+	if len(g.pendingUpdates) != 0 {
+		t.Fatalf("first call emitted %v, want only a base", g.pendingUpdates)
+	}
+	g.emitDelta()
+	if len(g.pendingUpdates) != 0 {
+		t.Fatalf("unchanged board emitted %v", g.pendingUpdates)
+	}
+
+	g.Panel[3][4] = "D"
+	g.Players[1].Lives--
+	g.Bombs = append(g.Bombs, testBomb(g, 0, 1, 1, time.Second))
+	g.emitDelta()
+	if len(g.pendingUpdates) != 1 {
+		t.Fatalf("%d updates, want one Delta", len(g.pendingUpdates))
+	}
+	delta, ok := g.pendingUpdates[0].(DeltaMsg)
+	if !ok {
+		t.Fatalf("emitted %T, want DeltaMsg", g.pendingUpdates[0])
+	}
+	if len(delta.Cells) != 1 || delta.Cells[0] != (CellChange{Row: 3, Col: 4, Value: "D"}) {
+		t.Errorf("cells %+v, want the one changed cell", delta.Cells)
+	}
+	// Player 0 changed too: placing the bomb used one of theirs
+	if len(delta.Players) != 2 {
+		t.Errorf("%d players in the delta, want 2", len(delta.Players))
+	}
+	if len(delta.Bombs) != 1 {
+		t.Errorf("%d bombs in the delta, want 1", len(delta.Bombs))
+	}
+	if delta.Powerups != nil {
+		t.Errorf("powerups %+v, want none since they did not change", delta.Powerups)
+	}
+
+	g.pendingUpdates = nil
+	g.Players[1].Lives--
+	g.emitDelta()
+	delta = g.pendingUpdates[0].(DeltaMsg)
+	if len(delta.Players) != 1 || delta.Players[0].Index != 1 || delta.Cells != nil || delta.Bombs != nil {
+		t.Errorf("delta %+v, want only player 1", delta)
+	}
+}
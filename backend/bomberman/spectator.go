@@ -0,0 +1,54 @@
+package bomberman
+
+import (
+	"log"
+	"net/http"
+)
+
+// HandleSpectatorConnection registers a read-only connection that receives every broadcast
+// of this room. Spectators never take a player slot and anything they send is ignored.
+func (g *GameBoard) HandleSpectatorConnection(w http.ResponseWriter, r *http.Request) {
+	conn, err := Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("Upgrade error:", err)
+		return
+	}
+
+	g.Mu.Lock()
+	spectatorID := g.nextSpectatorID
+	g.nextSpectatorID++
+	g.SpectatorConnections[spectatorID] = conn
+	snapshot := g.Snapshot()
+	g.Mu.Unlock()
+	log.Printf("Spectator %d joined room %s\n", spectatorID, g.ID)
+
+	g.SendMsgToSpectator(struct {
+		Type string `json:"type"`
+		ID   int    `json:"id"`
+	}{
+		Type: "SpectatorAccepted",
+		ID:   spectatorID,
+	}, spectatorID)
+	g.SendMsgToSpectator(snapshot, spectatorID)
+
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
+		log.Printf("Ignoring %v message from spectator %d\n", msg["msgType"], spectatorID)
+	}
+
+	g.Mu.Lock()
+	if g.SpectatorConnections[spectatorID] == conn {
+		delete(g.SpectatorConnections, spectatorID)
+	}
+	g.Mu.Unlock()
+	conn.Close()
+	log.Printf("Spectator %d left room %s\n", spectatorID, g.ID)
+}
+
+// SendMsgToSpectator queues a message that is delivered only to the given spectator.
+func (g *GameBoard) SendMsgToSpectator(msg any, spectatorID int) {
+	g.SendMsgToChannel(targetedMsg{Spectators: []int{spectatorID}, Msg: msg}, -1)
+}
@@ -0,0 +1,63 @@
+package bomberman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSpectatorPayloadsHaveNoUUIDs(t *testing.T) {
+	m := NewRoomManager()
+	g, err := m.CreateRoom(RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.RemoveRoom(g.ID)
+
+	var uuids []string
+	g.Mu.Lock()
+	for _, name := range []string{"alice", "bob"} {
+		uuid, err := g.CreatePlayer(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	g.Mu.Unlock()
+
+	server := httptest.NewServer(http.HandlerFunc(g.HandleSpectatorConnection))
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// SpectatorAccepted, Snapshot, then a broadcast player_list
+	g.Mu.Lock()
+	playerList := g.PlayerListMsg()
+	g.Mu.Unlock()
+	g.SendMsgToChannel(playerList, -1)
+	for _, want := range []string{"SpectatorAccepted", "Snapshot", "player_list"} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("reading %s: %v", want, err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("got %s, want a %s message", data, want)
+		}
+		for _, uuid := range uuids {
+			if strings.Contains(string(data), uuid) {
+				t.Errorf("%s message leaks a player UUID: %s", want, data)
+			}
+		}
+		if strings.Contains(string(data), `"uuid"`) {
+			t.Errorf("%s message has a uuid field: %s", want, data)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package bomberman
+
+import (
+	"log"
+	"time"
+)
+
+// Sudden death: once a match has run for Rules.SuddenDeath, indestructible blocks land
+// one by one along a spiral from the edges of the board to its center, so every match ends.
+
+// advanceSuddenDeath starts sudden death when its time has come and drops the blocks
+// that are due. Must be called with g.Mu held.
+func (g *GameBoard) advanceSuddenDeath() {
+	if g.GameState != "gameStarted" || !g.IsStarted || g.Rules.SuddenDeath <= 0 || g.StartedAt.IsZero() {
+		return
+	}
+	now := g.now()
+	if g.suddenDeathCells == nil {
+		if now.Sub(g.StartedAt) < g.Rules.SuddenDeath {
+			return
+		}
+		g.suddenDeathCells = spiralCells(g.Rows, g.Columns)
+		g.nextBlock = now
+		g.emit(StateMsg{Type: "GameState", State: "SuddenDeath"})
+		log.Printf("Room %s: sudden death\n", g.ID)
+	}
+
+	for len(g.suddenDeathCells) > 0 && !now.Before(g.nextBlock) && g.IsStarted {
+		cell := g.suddenDeathCells[0]
+		g.suddenDeathCells = g.suddenDeathCells[1:]
+		if g.Panel[cell[0]][cell[1]] == "W" {
+			continue
+		}
+		g.dropBlock(cell[0], cell[1])
+		g.nextBlock = g.nextBlock.Add(g.Rules.SuddenDeathInterval)
+	}
+}
+
+// dropBlock turns a cell into an indestructible wall, destroying the bombs and powerups
+// on it and crushing the players standing there until the match is over. Must be called with g.Mu held.
+func (g *GameBoard) dropBlock(row, col int) {
+	g.Panel[row][col] = "W"
+
+	for i := len(g.Bombs) - 1; i >= 0; i-- {
+		bomb := g.Bombs[i]
+		if bomb.Row != row || bomb.Column != col {
+			continue
+		}
+		if bomb.OwnPlayerIndex >= 0 && bomb.OwnPlayerIndex < len(g.Players) {
+			g.Players[bomb.OwnPlayerIndex].NumberOfUsedBombs--
+		}
+		g.Bombs = append(g.Bombs[:i], g.Bombs[i+1:]...)
+	}
+	for i := g.FindPowerupAt(row, col); i != -1; i = g.FindPowerupAt(row, col) {
+		g.RemovePowerup(i)
+	}
+	var remaining []ExplodedCellInfo
+	for _, info := range g.ExplodedCells {
+		if info.Position.Row != row || info.Position.Col != col {
+			remaining = append(remaining, info)
+		}
+	}
+	g.ExplodedCells = remaining
+
+	g.emit(BlockMsg{Type: "Block", Row: row, Col: col})
+
+	for i := range g.Players {
+		if !g.IsStarted {
+			return // The last crush ended the match, the result must not change any more
+		}
+		player := &g.Players[i]
+		if player.IsDead {
+			continue
+		}
+		centerRow := (player.YLocation + PlayerSize/2) / g.CellSize
+		centerCol := (player.XLocation + PlayerSize/2) / g.CellSize
+		if centerRow == row && centerCol == col {
+			g.crushPlayer(i)
+		}
+	}
+}
+
+// crushPlayer eliminates a player whatever lives they had left. Must be called with g.Mu held.
+func (g *GameBoard) crushPlayer(playerIndex int) {
+	log.Printf("Player %d crushed by sudden death\n", playerIndex)
+	g.Stats.LivesLost[CauseCrushed] += g.Players[playerIndex].Lives
+	var remaining []PlayerRespawn
+	for _, respawn := range g.PendingRespawns {
+		if respawn.PlayerIndex != playerIndex {
+			remaining = append(remaining, respawn)
+		}
+	}
+	g.PendingRespawns = remaining
+	g.PlayerDeath(playerIndex, -1, CauseCrushed)
+}
+
+// collapsingCells returns the cells sudden death fills within the given time.
+func (g *GameBoard) collapsingCells(within time.Duration) [][2]int {
+	if g.suddenDeathCells == nil {
+		return nil
+	}
+	var cells [][2]int
+	landing := g.nextBlock
+	deadline := g.now().Add(within)
+	for _, cell := range g.suddenDeathCells {
+		if landing.After(deadline) {
+			break
+		}
+		if g.Panel[cell[0]][cell[1]] == "W" {
+			continue
+		}
+		cells = append(cells, cell)
+		landing = landing.Add(g.Rules.SuddenDeathInterval)
+	}
+	return cells
+}
+
+// nearestOpenCell returns the empty cell closest to the given one, or the cell itself when the board is full.
+func (g *GameBoard) nearestOpenCell(row, col int) (int, int) {
+	inBoard := func(c [2]int) bool { return c[0] >= 0 && c[0] < g.Rows && c[1] >= 0 && c[1] < g.Columns }
+	path := g.botPath([2]int{row, col}, inBoard, func(c [2]int) bool { return g.Panel[c[0]][c[1]] == "" })
+	if len(path) == 0 {
+		return row, col
+	}
+	cell := path[len(path)-1]
+	return cell[0], cell[1]
+}
+
+// spiralCells lists every cell of a board, clockwise from the top-left corner inwards.
+func spiralCells(rows, columns int) [][2]int {
+	cells := make([][2]int, 0, rows*columns)
+	top, bottom, left, right := 0, rows-1, 0, columns-1
+	for top <= bottom && left <= right {
+		for col := left; col <= right; col++ {
+			cells = append(cells, [2]int{top, col})
+		}
+		for row := top + 1; row <= bottom; row++ {
+			cells = append(cells, [2]int{row, right})
+		}
+		if top < bottom {
+			for col := right - 1; col >= left; col-- {
+				cells = append(cells, [2]int{bottom, col})
+			}
+		}
+		if left < right {
+			for row := bottom - 1; row > top; row-- {
+				cells = append(cells, [2]int{row, left})
+			}
+		}
+		top, bottom, left, right = top+1, bottom-1, left+1, right-1
+	}
+	return cells
+}
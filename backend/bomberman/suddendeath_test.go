@@ -0,0 +1,75 @@
+package bomberman
+
+import (
+	"testing"
+	"time"
+)
+
+// newSuddenDeathBoard returns a board whose sudden death is due, with the given cells still to fill.
+func newSuddenDeathBoard(cells [][2]int, players ...[2]int) *GameBoard {
+	g := newBombTestBoard(players...)
+	g.Rules.SuddenDeath = time.Minute
+	g.StartedAt = g.now().Add(-time.Minute)
+	g.suddenDeathCells = cells
+	g.nextBlock = g.now()
+	return g
+}
+
+func TestSuddenDeathBlockCrushesPlayers(t *testing.T) {
+	g := newSuddenDeathBoard([][2]int{{1, 1}, {1, 2}}, [2]int{1, 1}, [2]int{9, 11}, [2]int{9, 1})
+	g.Bombs = []Bomb{testBomb(g, 1, 1, 2, time.Hour)}
+
+	g.advanceSuddenDeath() // Only the first block is due
+
+	if !g.Players[0].IsDead || g.Players[0].Lives != 0 {
+		t.Fatalf("player 0 under the block has %d lives, want crushed", g.Players[0].Lives)
+	}
+	if g.Panel[1][1] != "W" || g.Panel[1][2] == "W" {
+		t.Fatalf("panel row 1 is %v, want only the first block", g.Panel[1][:3])
+	}
+	if !g.IsStarted {
+		t.Fatal("the match ended with two players left")
+	}
+
+	g.nextBlock = g.now()
+	g.advanceSuddenDeath()
+	if len(g.Bombs) != 0 || g.Players[1].NumberOfUsedBombs != 0 {
+		t.Fatalf("the bomb under the second block survived: %+v", g.Bombs)
+	}
+}
+
+func TestSuddenDeathStopsOnceTheMatchIsOver(t *testing.T) {
+	// Both players stand on the cell of the first block: crushing the first ends the match
+	g := newSuddenDeathBoard([][2]int{{1, 1}, {9, 11}}, [2]int{1, 1}, [2]int{1, 1})
+	g.nextBlock = g.now().Add(-time.Hour) // Every block is due
+
+	g.advanceSuddenDeath()
+
+	if g.IsStarted {
+		t.Fatal("the match should be over")
+	}
+	dead := 0
+	for _, player := range g.Players {
+		if player.IsDead {
+			dead++
+		}
+		if player.Deaths > 1 {
+			t.Errorf("player %d died %d times", player.Index, player.Deaths)
+		}
+	}
+	if dead != 1 {
+		t.Fatalf("%d players crushed, want only the one that ended the match", dead)
+	}
+	if g.Panel[9][11] == "W" {
+		t.Error("a block dropped after the match was over")
+	}
+	gameOvers := 0
+	for _, update := range g.pendingUpdates {
+		if msg, ok := update.(map[string]interface{}); ok && msg["state"] == "GameOver" {
+			gameOvers++
+		}
+	}
+	if gameOvers != 1 {
+		t.Errorf("%d GameOver messages, want 1", gameOvers)
+	}
+}
@@ -0,0 +1,160 @@
+package bomberman
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// In team mode the players play 2v2: a match ends when only one team has survivors.
+
+func (g *GameBoard) isTeamMode() bool {
+	return g.Mode == ModeTeams
+}
+
+// teamSize returns how many players are on a team. Must be called with g.Mu held.
+func (g *GameBoard) teamSize(team int) int {
+	count := 0
+	for _, player := range g.Players {
+		if player.Team == team {
+			count++
+		}
+	}
+	return count
+}
+
+// smallestTeam returns the team a new player is assigned to, team 1 on a tie.
+// Must be called with g.Mu held.
+func (g *GameBoard) smallestTeam() int {
+	smallest := 1
+	for team := 2; team <= NumberOfTeams; team++ {
+		if g.teamSize(team) < g.teamSize(smallest) {
+			smallest = team
+		}
+	}
+	return smallest
+}
+
+// SetTeam moves a player to another team while the room is in the lobby.
+// Must be called with g.Mu held.
+func (g *GameBoard) SetTeam(playerIndex, team int) error {
+	if !g.isTeamMode() {
+		return errors.New("the room is not in team mode")
+	}
+	if g.IsStarted {
+		return errors.New("teams can only be changed in the lobby")
+	}
+	if team < 1 || team > NumberOfTeams {
+		return fmt.Errorf("team must be from 1 to %d", NumberOfTeams)
+	}
+	if g.Players[playerIndex].Team == team {
+		return nil
+	}
+	if g.teamSize(team) >= MaxTeamSize {
+		return fmt.Errorf("team %d is full", team)
+	}
+	g.Players[playerIndex].Team = team
+	log.Printf("Room %s: player %d joined team %d\n", g.ID, playerIndex, team)
+	return nil
+}
+
+func (g *GameBoard) HandleTeamMessage(playerIndex, team int) {
+	g.Mu.Lock()
+	if !g.validPlayer(playerIndex) {
+		g.Mu.Unlock()
+		g.SendError(playerIndex, &ProtocolError{Code: ErrCodeInvalidPlayer, MsgType: "team", Message: "player is not in the game"})
+		return
+	}
+	if err := g.SetTeam(playerIndex, team); err != nil {
+		g.Mu.Unlock()
+		g.SendError(playerIndex, &ProtocolError{Code: ErrCodeNotAllowed, MsgType: "team", Message: err.Error()})
+		return
+	}
+	msg := g.PlayerListMsg()
+	g.Mu.Unlock()
+	g.SendMsgToChannel(msg, -1)
+}
+
+// balanceTeams makes sure no team is empty when the match starts, by moving the last
+// players of the bigger team. Must be called with g.Mu held.
+func (g *GameBoard) balanceTeams() {
+	if !g.isTeamMode() {
+		return
+	}
+	for i := len(g.Players) - 1; i >= 0; i-- {
+		if g.teamSize(1) > 0 && g.teamSize(2) > 0 {
+			return
+		}
+		other := 3 - g.Players[i].Team
+		if g.teamSize(g.Players[i].Team) > 1 {
+			log.Printf("Room %s: moved player %d to team %d to balance the teams\n", g.ID, i, other)
+			g.Players[i].Team = other
+		}
+	}
+}
+
+// areOpponents tells whether two players play against each other.
+func (g *GameBoard) areOpponents(a, b int) bool {
+	if a == b {
+		return false
+	}
+	return !g.isTeamMode() || g.Players[a].Team != g.Players[b].Team
+}
+
+// PlayerListMsg builds the player_list message for the current lobby. Must be called with g.Mu held.
+func (g *GameBoard) PlayerListMsg() PlayerListMsg {
+	msg := PlayerListMsg{
+		Type:    "player_list",
+		Players: append([]Player(nil), g.Players...),
+		Mode:    g.Mode,
+	}
+	if g.isTeamMode() {
+		msg.Teams = make([][]int, NumberOfTeams)
+		for i, player := range g.Players {
+			if player.Team >= 1 && player.Team <= NumberOfTeams {
+				msg.Teams[player.Team-1] = append(msg.Teams[player.Team-1], i)
+			}
+		}
+	}
+	return msg
+}
+
+// checkTeamGameEnd ends a team match once at most one team has survivors. Must be called with g.Mu held.
+func (g *GameBoard) checkTeamGameEnd() {
+	alive := make(map[int]bool)
+	for _, player := range g.Players {
+		if player.Lives > 0 {
+			alive[player.Team] = true
+		}
+	}
+	if len(alive) > 1 || !g.IsStarted {
+		return
+	}
+
+	team := 0
+	for t := range alive {
+		team = t
+	}
+	winners := []int{}
+	for i, player := range g.Players {
+		if team != 0 && player.Team == team {
+			winners = append(winners, i)
+		}
+	}
+	winner := -1
+	if len(winners) > 0 {
+		winner = winners[0]
+	}
+	if team != 0 {
+		log.Printf("Game over! Team %d wins\n", team)
+	} else {
+		log.Printf("Game over! It's a draw.")
+	}
+	g.endMatch(map[string]interface{}{
+		"type":    "GameState",
+		"state":   "GameOver",
+		"winner":  winner,
+		"team":    team,
+		"winners": winners,
+	}, winners)
+}
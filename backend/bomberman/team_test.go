@@ -0,0 +1,90 @@
+package bomberman
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTeamTestBoard puts players 0 and 1 on team 1 and players 2 and 3 on team 2.
+func newTeamTestBoard(cells ...[2]int) *GameBoard {
+	g := newBombTestBoard(cells...)
+	g.Mode = ModeTeams
+	for i := range g.Players {
+		g.Players[i].Team = i/2 + 1
+	}
+	return g
+}
+
+func TestTeamFriendlyFire(t *testing.T) {
+	for _, friendlyFire := range []bool{false, true} {
+		// Player 0's bomb at (1,3) reaches its owner, a teammate and an opponent
+		g := newTeamTestBoard([2]int{1, 1}, [2]int{1, 5}, [2]int{3, 3}, [2]int{9, 11})
+		g.FriendlyFire = friendlyFire
+		g.Bombs = []Bomb{testBomb(g, 0, 1, 3, -time.Second)}
+
+		g.checkBombs()
+
+		want := map[int]bool{0: true, 1: friendlyFire, 2: true, 3: false}
+		for i, hurt := range want {
+			if lost := g.Players[i].Lives < g.Rules.Lives; lost != hurt {
+				t.Errorf("friendly fire %v: player %d lost a life = %v, want %v", friendlyFire, i, lost, hurt)
+			}
+		}
+	}
+}
+
+func TestTeamMatchEndsWhenOneTeamIsLeft(t *testing.T) {
+	g := newTeamTestBoard([2]int{1, 1}, [2]int{1, 11}, [2]int{9, 1}, [2]int{9, 11})
+
+	g.PlayerDeath(0, 2, CauseOpponentBomb)
+	g.PlayerDeath(3, 1, CauseOpponentBomb)
+	if !g.IsStarted {
+		t.Fatal("the match ended while both teams have a survivor")
+	}
+	g.PlayerDeath(1, 2, CauseOpponentBomb)
+	if g.IsStarted {
+		t.Fatal("the match goes on with only team 2 left")
+	}
+
+	var gameOver map[string]interface{}
+	for _, update := range g.pendingUpdates {
+		if msg, ok := update.(map[string]interface{}); ok && msg["state"] == "GameOver" {
+			gameOver = msg
+		}
+	}
+	if gameOver == nil {
+		t.Fatal("no GameOver message")
+	}
+	if gameOver["team"] != 2 || !reflect.DeepEqual(gameOver["winners"], []int{2, 3}) {
+		t.Errorf("GameOver %v, want team 2 with players 2 and 3, the fallen one included", gameOver)
+	}
+}
+
+func TestSetTeamAndBalance(t *testing.T) {
+	g := newTeamTestBoard([2]int{1, 1}, [2]int{1, 11}, [2]int{9, 1})
+	g.IsStarted = false
+	g.GameState = "lobby"
+
+	if err := g.SetTeam(2, 1); err == nil {
+		t.Error("joined team 1 while it is full")
+	}
+	if err := g.SetTeam(0, 3); err == nil {
+		t.Error("joined a team that does not exist")
+	}
+	if err := g.SetTeam(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i := range g.Players {
+		g.Players[i].Team = 1
+	}
+	g.balanceTeams()
+	if g.teamSize(1) == 0 || g.teamSize(2) == 0 {
+		t.Errorf("teams of %d and %d after balancing, want nobody alone", g.teamSize(1), g.teamSize(2))
+	}
+
+	g.IsStarted = true
+	if err := g.SetTeam(0, 2); err == nil {
+		t.Error("changed team during the match")
+	}
+}
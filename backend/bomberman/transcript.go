@@ -0,0 +1,97 @@
+package bomberman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Add keeps a finished match's transcript, forgetting the oldest beyond MaxTranscripts.
+func (s *TranscriptStore) Add(t Transcript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transcripts = append(s.transcripts, t)
+	if len(s.transcripts) > MaxTranscripts {
+		s.transcripts = append([]Transcript(nil), s.transcripts[len(s.transcripts)-MaxTranscripts:]...)
+	}
+}
+
+// Get returns the transcript with the given ID.
+func (s *TranscriptStore) Get(id string) (Transcript, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.transcripts {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Transcript{}, false
+}
+
+// Latest returns the transcript of the last match finished in a room.
+func (s *TranscriptStore) Latest(room string) (Transcript, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.transcripts) - 1; i >= 0; i-- {
+		if s.transcripts[i].Room == room {
+			return s.transcripts[i], true
+		}
+	}
+	return Transcript{}, false
+}
+
+// matchID names the match that just ended: its replay ID when it was recorded. Must be called with g.Mu held.
+func (g *GameBoard) matchID() string {
+	if g.recorder != nil {
+		return g.recorder.ID
+	}
+	return fmt.Sprintf("%s-%d", g.ID, time.Now().Unix())
+}
+
+// saveTranscript stores the chat of the match that just ended. Must be called with g.Mu held.
+func (g *GameBoard) saveTranscript(id string) {
+	if g.Transcripts == nil {
+		return
+	}
+	g.Transcripts.Add(Transcript{
+		ID:       id,
+		Room:     g.ID,
+		EndedAt:  time.Now(),
+		Messages: append([]Chat{}, g.matchChat...),
+	})
+}
+
+// TranscriptHandler returns the chat transcript of a finished match as JSON.
+// Query parameters: 'id', the match ID sent with GameOver, or 'room' for the last match of a room.
+func (m *RoomManager) TranscriptHandler(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	id := r.URL.Query().Get("id")
+	room := r.URL.Query().Get("room")
+	if id == "" && room == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "ID or room parameter is required."})
+		return
+	}
+	var transcript Transcript
+	found := false
+	if m.Transcripts != nil {
+		if id != "" {
+			transcript, found = m.Transcripts.Get(id)
+		} else {
+			transcript, found = m.Transcripts.Latest(room)
+		}
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Transcript not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(transcript)
+}
@@ -0,0 +1,35 @@
+package bomberman
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTranscriptKeepsTheWholeMatchChat(t *testing.T) {
+	g := newBombTestBoard([2]int{1, 1}, [2]int{9, 11})
+	g.Transcripts = &TranscriptStore{}
+	total := ChatHistorySize + 20
+	for i := 0; i < total; i++ {
+		g.sendPublicChat(i%2, ChatKindMessage, fmt.Sprintf("message %d", i))
+	}
+
+	if n := len(g.ChatHistoryMsg().Messages); n != ChatHistorySize {
+		t.Fatalf("chat history has %d messages, want %d", n, ChatHistorySize)
+	}
+	g.saveTranscript("match")
+	transcript, ok := g.Transcripts.Get("match")
+	if !ok {
+		t.Fatal("transcript not saved")
+	}
+	if len(transcript.Messages) != total {
+		t.Fatalf("transcript has %d messages, want %d", len(transcript.Messages), total)
+	}
+	if first := transcript.Messages[0].Content; first != "message 0" {
+		t.Fatalf("transcript starts with %q, want the first message", first)
+	}
+
+	g.ResetGame()
+	if len(g.matchChat) != 0 {
+		t.Fatalf("%d messages left for the next match after a reset", len(g.matchChat))
+	}
+}
@@ -2,22 +2,100 @@ package main
 
 import (
 	"bomberman-dom/backend/bomberman"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 )
 
 func main() {
-	game := bomberman.InitGame()
-	game.RandomStart()
-	go game.StartBroadcaster()
+	tickRate := flag.Int("tickrate", bomberman.DefaultTickRate, "simulation ticks per second")
+	replayDir := flag.String("replays", "replays", "directory for match replays, empty to disable recording")
+	mapDir := flag.String("maps", "maps", "directory of custom map files")
+	bannedFile := flag.String("banned-words", "", "file of words masked in the chat, one per line")
+	mutedFile := flag.String("muted", "", "file of player names muted on the whole server, one per line")
+	adminToken := flag.String("admin-token", os.Getenv("BOMBERMAN_ADMIN_TOKEN"), "secret of the admin API, sent in the X-Admin-Token header; empty disables the API")
+	profilesFile := flag.String("profiles", "profiles.json", "file keeping the player profiles, empty to disable")
+	rulesFile := flag.String("rules", "", "JSON file with the game rules, rule flags override it")
+	ruleFlags := bomberman.RuleFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *tickRate <= 0 {
+		log.Fatal("The tick rate must be a positive number of ticks per second")
+	}
+
+	rules, err := bomberman.LoadRules(*rulesFile)
+	if err == nil {
+		err = rules.Apply(ruleFlags)
+	}
+	if err != nil {
+		log.Fatal("Loading rules: ", err)
+	}
+
+	maps, err := bomberman.LoadMaps(*mapDir)
+	if err != nil {
+		log.Fatal("Loading maps: ", err)
+	}
+
+	var profiles *bomberman.ProfileStore
+	if *profilesFile != "" {
+		profiles, err = bomberman.OpenProfileStore(*profilesFile)
+		if err != nil {
+			log.Fatal("Loading profiles: ", err)
+		}
+	}
+
+	var bannedWords, muted []string
+	if *bannedFile != "" {
+		if bannedWords, err = bomberman.ReadWordList(*bannedFile); err != nil {
+			log.Fatal("Loading banned words: ", err)
+		}
+	}
+	if *mutedFile != "" {
+		if muted, err = bomberman.ReadWordList(*mutedFile); err != nil {
+			log.Fatal("Loading muted players: ", err)
+		}
+	}
+	moderator := bomberman.NewChatModerator(bannedWords)
+	for _, name := range muted {
+		moderator.Mute(name, 0)
+	}
+
+	rooms := bomberman.NewRoomManager()
+	rooms.TickRate = *tickRate
+	rooms.ReplayDir = *replayDir
+	rooms.Maps = maps
+	rooms.Rules = rules
+	rooms.Profiles = profiles
+	rooms.Moderator = moderator
+	rooms.AdminToken = *adminToken
 
 	// Bind the /ws route
-	http.HandleFunc("/ws", game.HandleWSConnections)
-	http.HandleFunc("/checkName", game.CheckNameHandler)
+	http.HandleFunc("/ws", rooms.HandleWSConnections)
+	http.HandleFunc("/checkName", rooms.CheckNameHandler)
+	http.HandleFunc("/spectate", rooms.SpectateHandler)
+	http.HandleFunc("/rooms", rooms.ListRoomsHandler)
+	http.HandleFunc("/maps", rooms.MapsHandler)
+	http.HandleFunc("/replays", rooms.ReplaysHandler)
+	http.HandleFunc("/replay", rooms.ReplayHandler)
+	http.HandleFunc("/leaderboard", rooms.LeaderboardHandler)
+	http.HandleFunc("/profile", rooms.ProfileHandler)
+	http.HandleFunc("/transcript", rooms.TranscriptHandler)
+	http.HandleFunc("/metrics", rooms.MetricsHandler)
+
+	// Admin API, see WSmessages.md
+	http.HandleFunc("/admin/board", rooms.AdminHandler("GET", rooms.AdminBoard))
+	http.HandleFunc("/admin/players", rooms.AdminHandler("GET", rooms.AdminPlayers))
+	http.HandleFunc("/admin/kick", rooms.AdminHandler("POST", rooms.AdminKick))
+	http.HandleFunc("/admin/start", rooms.AdminHandler("POST", rooms.AdminStart))
+	http.HandleFunc("/admin/reset", rooms.AdminHandler("POST", rooms.AdminReset))
+	http.HandleFunc("/admin/broadcast", rooms.AdminHandler("POST", rooms.AdminBroadcast))
+	http.HandleFunc("/admin/mute", rooms.AdminHandler("POST", rooms.AdminMute))
+	http.HandleFunc("/admin/unmute", rooms.AdminHandler("POST", rooms.AdminUnmute))
 
 	// Start the server
 	log.Println("Server started at :8080")
-	err := http.ListenAndServe(":8080", nil)
+	err = http.ListenAndServe(":8080", nil)
 	if err != nil {
 		log.Fatal("ListenAndServe:", err)
 	}
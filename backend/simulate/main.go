@@ -0,0 +1,229 @@
+// Command simulate runs bot-only matches headlessly, faster than real time, and prints
+// aggregate results: wins by spawn corner, match length, deaths by cause and powerups.
+//
+//	go run ./backend/simulate -matches 200 -bots hard,normal,normal,easy -format csv
+package main
+
+import (
+	"bomberman-dom/backend/bomberman"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Summary struct {
+	Matches          int            `json:"matches"`
+	Draws            int            `json:"draws"`
+	TimedOut         int            `json:"timedOut"`
+	WinsBySpawn      map[string]int `json:"winsBySpawn"`
+	WinsByLevel      map[string]int `json:"winsByLevel"`
+	AverageSeconds   float64        `json:"averageSeconds"`
+	AverageTicks     float64        `json:"averageTicks"`
+	LivesLostByCause map[string]int `json:"livesLostByCause"`
+	DeathsByCause    map[string]int `json:"deathsByCause"`
+	PowerupsByType   map[string]int `json:"powerupsByType"`
+}
+
+func main() {
+	matches := flag.Int("matches", 100, "number of matches to run")
+	seed := flag.Int64("seed", 0, "seed of the first match, the others use the following seeds; 0 for random")
+	bots := flag.String("bots", "normal,normal,normal,normal", "comma separated bot levels, one bot per spawn")
+	mapName := flag.String("map", "", "custom map to play on, empty for random boards")
+	mapDir := flag.String("maps", "maps", "directory of custom map files")
+	rows := flag.Int("rows", 0, "rows of a random board, 0 for the default")
+	cols := flag.Int("cols", 0, "columns of a random board, 0 for the default")
+	tickRate := flag.Int("tickrate", bomberman.DefaultTickRate, "simulation ticks per simulated second")
+	maxTime := flag.Duration("max-time", 5*time.Minute, "simulated time after which a match is a draw")
+	format := flag.String("format", "json", "output format: json or csv")
+	workers := flag.Int("workers", runtime.NumCPU(), "matches run in parallel")
+	rulesFile := flag.String("rules", "", "JSON file with the game rules, rule flags override it")
+	ruleFlags := bomberman.RuleFlags(flag.CommandLine)
+	verbose := flag.Bool("v", false, "keep the game engine's logs")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	rules, err := bomberman.LoadRules(*rulesFile)
+	if err == nil {
+		err = rules.Apply(ruleFlags)
+	}
+	if err != nil {
+		fatal("Loading rules: %v", err)
+	}
+
+	opts := bomberman.SimOptions{
+		Rows:        *rows,
+		Columns:     *cols,
+		Rules:       rules,
+		TickRate:    *tickRate,
+		BotLevels:   strings.Split(*bots, ","),
+		MaxDuration: *maxTime,
+	}
+	if (*rows == 0) != (*cols == 0) {
+		fatal("-rows and -cols go together")
+	}
+	if *rows != 0 {
+		if err := bomberman.CheckBoardSize("-rows", *rows); err != nil {
+			fatal("%v", err)
+		}
+		if err := bomberman.CheckBoardSize("-cols", *cols); err != nil {
+			fatal("%v", err)
+		}
+	}
+	if *mapName != "" {
+		maps, err := bomberman.LoadMaps(*mapDir)
+		if err != nil {
+			fatal("Loading maps: %v", err)
+		}
+		if opts.Map = maps[*mapName]; opts.Map == nil {
+			fatal("Unknown map %q", *mapName)
+		}
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	results := make([]bomberman.MatchResult, *matches)
+	errs := make(chan error, *matches)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				matchOpts := opts
+				matchOpts.Seed = *seed + int64(i)
+				result, err := bomberman.SimulateMatch(matchOpts)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := 0; i < *matches; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		fatal("Simulation: %v", err)
+	}
+
+	summary := summarize(results, opts.BotLevels)
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(summary)
+	case "csv":
+		writeCSV(os.Stdout, summary)
+	default:
+		fatal("Unknown format %q", *format)
+	}
+}
+
+func summarize(results []bomberman.MatchResult, levels []string) Summary {
+	summary := Summary{
+		Matches:          len(results),
+		WinsBySpawn:      make(map[string]int),
+		WinsByLevel:      make(map[string]int),
+		LivesLostByCause: make(map[string]int),
+		DeathsByCause:    make(map[string]int),
+		PowerupsByType:   make(map[string]int),
+	}
+	for _, result := range results {
+		summary.AverageSeconds += result.Duration
+		summary.AverageTicks += float64(result.Ticks)
+		switch {
+		case result.TimedOut:
+			summary.TimedOut++
+		case result.Winner == -1:
+			summary.Draws++
+		default:
+			summary.WinsBySpawn[spawnName(result, result.Winner)]++
+			summary.WinsByLevel[levels[result.Winner]]++
+		}
+		addCounts(summary.LivesLostByCause, result.Stats.LivesLost)
+		addCounts(summary.DeathsByCause, result.Stats.Eliminations)
+		addCounts(summary.PowerupsByType, result.Stats.Powerups)
+	}
+	if len(results) > 0 {
+		summary.AverageSeconds /= float64(len(results))
+		summary.AverageTicks /= float64(len(results))
+	}
+	return summary
+}
+
+// spawnName names a spawn by the corner of the board it is closest to, e.g. "1:top-left".
+func spawnName(result bomberman.MatchResult, player int) string {
+	rows, cols := 0, 0
+	for _, spawn := range result.Spawns {
+		rows = max(rows, spawn[0])
+		cols = max(cols, spawn[1])
+	}
+	spawn := result.Spawns[player]
+	vertical, horizontal := "top", "left"
+	if spawn[0]*2 > rows {
+		vertical = "bottom"
+	}
+	if spawn[1]*2 > cols {
+		horizontal = "right"
+	}
+	return fmt.Sprintf("%d:%s-%s", player+1, vertical, horizontal)
+}
+
+func addCounts(total, counts map[string]int) {
+	for key, n := range counts {
+		total[key] += n
+	}
+}
+
+// writeCSV prints the summary as metric,key,value rows.
+func writeCSV(w io.Writer, summary Summary) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"metric", "key", "value"})
+	out.Write([]string{"matches", "", fmt.Sprint(summary.Matches)})
+	out.Write([]string{"draws", "", fmt.Sprint(summary.Draws)})
+	out.Write([]string{"timedOut", "", fmt.Sprint(summary.TimedOut)})
+	out.Write([]string{"averageSeconds", "", fmt.Sprintf("%.2f", summary.AverageSeconds)})
+	out.Write([]string{"averageTicks", "", fmt.Sprintf("%.1f", summary.AverageTicks)})
+	for _, group := range []struct {
+		metric string
+		counts map[string]int
+	}{
+		{"winsBySpawn", summary.WinsBySpawn},
+		{"winsByLevel", summary.WinsByLevel},
+		{"livesLostByCause", summary.LivesLostByCause},
+		{"deathsByCause", summary.DeathsByCause},
+		{"powerupsByType", summary.PowerupsByType},
+	} {
+		keys := make([]string, 0, len(group.counts))
+		for key := range group.counts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			out.Write([]string{group.metric, key, fmt.Sprint(group.counts[key])})
+		}
+	}
+	out.Flush()
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -25,10 +25,30 @@ export function renderChat(messages) {
     const renderMessage = (msg) => {
         const { playerIndex } = store.getState();
         const isSent = msg.senderIndex === playerIndex;
-        const bubbleClass = isSent ? 'message-bubble sent' : 'message-bubble received';
-        const sender = isSent ? 'You' : msg.player;
+        const bubbleClass = (isSent ? 'message-bubble sent' : 'message-bubble received') + (msg.kind ? ` ${msg.kind}` : '');
+        let sender = isSent ? 'You' : msg.player;
         const timestamp = new Date().toLocaleTimeString([], { hour: '2-digit', minute: '2-digit', hour12: false });
 
+        if (msg.kind === 'system') {
+            return createElement('div', { class: 'chat-message' },
+                createElement('div', { class: 'message-bubble system' },
+                    createElement('div', { class: 'message-content' }, msg.message)
+                )
+            );
+        }
+        if (msg.kind === 'emote') {
+            return createElement('div', { class: 'chat-message' },
+                createElement('div', { class: 'message-bubble emote' },
+                    createElement('div', { class: 'message-content' }, `* ${msg.player} ${msg.message}`)
+                )
+            );
+        }
+        if (msg.kind === 'whisper') {
+            sender = isSent ? `To ${msg.to}` : `${msg.player} (whisper)`;
+        } else if (msg.kind === 'team') {
+            sender = `[Team] ${sender}`;
+        }
+
         return createElement('div', { class: 'chat-message' },
             createElement('div', { class: bubbleClass },
                 createElement('div', { class: 'message-sender', style: `color: ${msg.color}` }, sender),
@@ -52,7 +72,7 @@ export function renderChat(messages) {
             ...messages.map(renderMessage)
         ),
         createElement('form', { class: 'chat-input-form', onsubmit: handleSubmit },
-            createElement('input', { type: 'text', name: 'message', placeholder: 'Type a message...' }),
+            createElement('input', { type: 'text', name: 'message', placeholder: 'Type a message or /help...' }),
             createElement('button', { type: 'submit' }, '➤')
         )
     );
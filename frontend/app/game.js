@@ -16,7 +16,12 @@ const powerupTypes = [
     { name: 'Extra Bomb', type: 'ExtraBomb', image: '/public/images/whiteegg.png', description: 'Increases bomb capacity by one.' },
     { name: 'Bomb Range', type: 'BombRange', image: '/public/images/extrab.webp', description: 'Increases bomb explosion range.' },
     { name: 'Extra Life', type: 'ExtraLife', image: '/public/images/life.webp', description: 'Grants an extra life.' },
-    { name: 'Speed Boost', type: 'SpeedBoost', image: '/public/images/fast.webp', description: 'Increases movement speed.' }
+    { name: 'Speed Boost', type: 'SpeedBoost', image: '/public/images/fast.webp', description: 'Increases movement speed.' },
+    { name: 'Kick', type: 'Kick', image: '/public/images/greenegg.png', description: 'Walk into a bomb to send it sliding.' },
+    { name: 'Remote', type: 'Remote', image: '/public/images/bomb.svg', description: 'Your bombs wait until you press D.' },
+    { name: 'Pierce', type: 'Pierce', image: '/public/images/burn.svg', description: 'Blasts go through destructible walls.' },
+    { name: 'Bomb Pass', type: 'BombPass', image: '/public/images/penguin.gif', description: 'Walk through bombs.' },
+    { name: 'Wall Pass', type: 'WallPass', image: '/public/images/solidwallSnow.svg', description: 'Walk through destructible walls.' }
 ];
 
 // Set to track currently pressed movement keys
@@ -47,6 +52,8 @@ const handleKeyEvent = (e, isKeyDown) => {
         }
     } else if (key === 'Space' && isKeyDown) {
         sendMsg({ msgType: 'b' }); // Only send bomb on keydown
+    } else if (key === 'KeyD' && isKeyDown) {
+        sendMsg({ msgType: 'detonate' }); // Sets off the oldest remote bomb
     }
 };
 
@@ -97,11 +104,37 @@ function renderPlayerPanel(player) {
         createElement('div', { class: avatarClass }),
         createElement('div', { class: 'player-info' },
             createElement('h3', {}, player.name),
-            createElement('p', {}, player.lives > 0 ? '🩵'.repeat(player.lives) : 'Dead 💀')
+            createElement('p', {}, player.lives > 0 ? '🩵'.repeat(player.lives) : 'Dead 💀'),
+            createElement('p', {}, `Score: ${player.score || 0}`)
         )
     );
 }
 
+const killFeedCauses = {
+    opponentBomb: 'blew up',
+    ownBomb: 'blew up',
+    chain: 'chain-blasted',
+    fire: 'burned',
+};
+
+// Render the latest kill feed entries
+function renderKillFeed(killFeed, players) {
+    const name = (index) => (players[index] ? players[index].name : '?');
+    return createElement('div', { class: 'kill-feed' },
+        ...killFeed.map(entry => {
+            let text;
+            if (entry.killer === entry.victim) {
+                text = `${name(entry.victim)} blew themselves up`;
+            } else if (entry.killer >= 0) {
+                text = `${name(entry.killer)} ${killFeedCauses[entry.cause] || 'hit'} ${name(entry.victim)}`;
+            } else {
+                text = `${name(entry.victim)} was ${entry.cause === 'crushed' ? 'crushed' : 'lost'}`;
+            }
+            return createElement('p', { class: entry.fatal ? 'kill-feed-entry fatal' : 'kill-feed-entry' }, text);
+        })
+    );
+}
+
 // Render the game grid
 function renderGameGrid(panel, players, powerups) {
     const { playerAnimation } = store.getState();
@@ -183,9 +216,9 @@ function renderGameGrid(panel, players, powerups) {
         ...borderedPanel.map(row =>
             createElement('div', { class: 'grid-row' },
                 ...row.map(cell => {
-                    if (cell === 'B') {
+                    if (cell === 'B' || cell === 'RB') {
                         return createElement('div', { class: 'grid-cell' },
-                            createElement('img', { src: '/public/images/redegg.png', class: 'bomb-image' })
+                            createElement('img', { src: '/public/images/redegg.png', class: cell === 'RB' ? 'bomb-image remote' : 'bomb-image' })
                         );
                     } else if (cell === 'E') {
                         return createElement('div', { class: 'grid-cell E' });
@@ -201,7 +234,7 @@ function renderGameGrid(panel, players, powerups) {
 }
 
 export function GameOverModal() {
-    const { winner, gameData } = store.getState();
+    const { winner, winningTeam, gameData } = store.getState();
     const { players } = gameData;
 
     const playAgainHandler = () => {
@@ -220,6 +253,7 @@ export function GameOverModal() {
             gameOver: false,
             gameData: null,
             chatMessages: [],
+            killFeed: [],
             gameListenersAttached: false,
             playerAnimation: new Map(),
             powerups: [],
@@ -230,7 +264,8 @@ export function GameOverModal() {
     return createElement('div', { class: 'modal' },
         createElement('div', { class: 'modal-content' },
             createElement('h2', {}, 'Game Over'),
-            (winner >= 0 && players[winner]) ? createElement('p', {}, `${players[winner].name} wins!`) : createElement('p', {}, "It's a draw!"),
+            winningTeam ? createElement('p', {}, `Team ${winningTeam} wins!`)
+                : (winner >= 0 && players[winner]) ? createElement('p', {}, `${players[winner].name} wins!`) : createElement('p', {}, "It's a draw!"),
             createElement('button', { class: 'play-again-btn', onclick: playAgainHandler }, 'Play Again')
         )
     );
@@ -257,7 +292,7 @@ function gameLoop() {
 
 // Main Game component
 export default function Game() {
-    const { countdown, gameStarted, gameData, chatMessages, gameListenersAttached, powerups, gameOver } = store.getState();
+    const { countdown, gameStarted, gameData, chatMessages, gameListenersAttached, powerups, gameOver, killFeed } = store.getState();
 
     if (gameStarted && !gameListenersAttached) {
         setupEventListeners();
@@ -280,6 +315,7 @@ export default function Game() {
                 createElement('h2', {}, 'How to Play'),
                 createElement('p', {}, 'Use the arrow keys to move your penguin.'),
                 createElement('p', {}, 'Press the spacebar to drop a bomb.'),
+                createElement('p', {}, 'With the Remote power-up, press D to set off your oldest bomb.'),
                 createElement('h2', {}, 'Power-ups'),
                 createElement('div', { id: 'powerups-container', class: 'powerups-container' }, ...powerupElements),
                 renderChat(chatMessages || [])
@@ -360,6 +396,7 @@ export default function Game() {
             ...players.map(renderPlayerPanel)
         ),
         mainGameArea,
+        renderKillFeed(killFeed || [], players),
         renderChat(chatMessages || []),
         gameOver ? GameOverModal(gameData) : null
     );
@@ -2,7 +2,8 @@ import { createElement, store } from '../framework/framework.js';
 import { renderChat } from './chat.js';
 
 export default function Lobby() {
-    const { players, countdown, playerId, chatMessages } = store.getState();
+    const { players, countdown, playerId, chatMessages, mode, ws } = store.getState();
+    const teamMode = mode === 'teams';
 
     const colorToImage = {
         "R": "/public/images/R_avatar.png",
@@ -22,6 +23,16 @@ export default function Lobby() {
 
         const playerName = createElement('span', {}, `${player.name} ${isYou ? '(You)' : ''}`);
 
+        // In team mode you can switch team by clicking your own entry
+        const switchTeam = () => {
+            if (ws) {
+                ws.send(JSON.stringify({ msgType: 'team', team: player.team === 1 ? 2 : 1 }));
+            }
+        };
+        const teamLabel = teamMode
+            ? createElement('span', { style: 'margin-left: auto; cursor: pointer;', onclick: isYou ? switchTeam : null }, `Team ${player.team}`)
+            : null;
+
         return createElement(
             'div',
             {
@@ -29,7 +40,8 @@ export default function Lobby() {
                 style: 'display: flex; align-items: center; margin-bottom: 8px;'
             },
             playerImage,
-            playerName
+            playerName,
+            teamLabel
         );
     });
 
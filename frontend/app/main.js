@@ -20,18 +20,42 @@ store.setState({
     gameListenersAttached: false, // Add this flag
     playerAnimation: new Map(), // For client-side animation
     powerups: [], // Add this line
+    killFeed: [], // Latest lives lost, newest last
 });
 
 const playerMoveTimers = new Map();
+// Cell of every bomb by ID, so a kicked bomb can be moved across the panel
+const bombCells = new Map();
+let lastSeq = null; // Sequence number of the last numbered message applied
 
 export function handleWebSocket() {
     const { ws } = store.getState();
     if (!ws) {
         return;
     }
+    lastSeq = null; // Every room numbers its messages from 1, start over on a new socket
 
     ws.onmessage = (event) => {
         const message = JSON.parse(event.data);
+        if (message.seq !== undefined && message.type !== 'Snapshot') {
+            if (lastSeq !== null && message.seq <= lastSeq) {
+                return; // Already covered by a snapshot
+            }
+            if (lastSeq !== null && message.seq > lastSeq + 1) {
+                // A message was lost, ask for the full state
+                ws.send(JSON.stringify({ msgType: 'resync' }));
+            }
+            lastSeq = message.seq;
+        }
+        // Simulation updates arrive batched, one batch per server tick
+        if (message.type === 'batch') {
+            message.updates.forEach(handleMessage);
+        } else {
+            handleMessage(message);
+        }
+    };
+
+    function handleMessage(message) {
         const { gameData, playerAnimation, chatMessages } = store.getState();
 
         // The backend sends messages with either a 'type' or an 'MT' property.
@@ -40,7 +64,7 @@ export function handleWebSocket() {
         if (message.type) {
             switch (message.type) {
                 case 'player_list':
-                    store.setState({ players: message.players });
+                    store.setState({ players: message.players, mode: message.mode });
                     break;
                 case 'GameState':
                     if (message.state === 'LobbyCountdown') {
@@ -52,11 +76,11 @@ export function handleWebSocket() {
                         store.setState({ countdown: null, gameStarted: true });
                     } else if (message.state === 'GameOver') {
                         console.log('Game over received')
-                        store.setState({ gameOver: true, winner: message.winner });
+                        store.setState({ gameOver: true, winner: message.winner, winningTeam: message.team || 0 });
                     }
                     break;
                 case 'PlayerAccepted':
-                    store.setState({ currentView: 'lobby', playerIndex: message.index });
+                    store.setState({ currentView: 'lobby', playerIndex: message.index, uuid: message.uuid });
                     break;
                 case 'PlayerDisconnected':
                     const playersList = store.getState().players;
@@ -72,13 +96,53 @@ export function handleWebSocket() {
                 case 'gameStart':
                     store.setState({ gameData: { players: message.players, panel: message.panel } });
                     break;
+                case 'Snapshot': {
+                    // Full board state, sent after reconnecting to a running match or on resync
+                    lastSeq = message.seq;
+                    const panel = message.panel.map(row => row.map(cell => cell === 'Ex' ? 'E' : cell));
+                    (message.bombs || []).forEach(bomb => {
+                        panel[bomb.row][bomb.column] = bomb.remote ? 'RB' : 'B';
+                    });
+                    store.setState({
+                        gameData: { players: message.players, panel },
+                        powerups: message.powerups || [],
+                        gameStarted: message.state === 'gameStarted',
+                    });
+                    break;
+                }
+                case 'Delta': {
+                    if (!gameData || !gameData.panel) {
+                        break;
+                    }
+                    const panel = gameData.panel.map(row => [...row]);
+                    (message.cells || []).forEach(cell => {
+                        panel[cell.row][cell.col] = cell.value === 'Ex' ? 'E' : cell.value;
+                    });
+                    if (message.bombs) {
+                        panel.forEach(row => row.forEach((cell, col) => {
+                            if (cell === 'B' || cell === 'RB') row[col] = '';
+                        }));
+                        message.bombs.forEach(bomb => {
+                            panel[bomb.row][bomb.column] = bomb.remote ? 'RB' : 'B';
+                        });
+                    }
+                    const players = gameData.players.map(p => (message.players || []).find(changed => changed.index === p.index) || p);
+                    store.setState({ gameData: { ...gameData, players, panel } });
+                    if (message.powerups) {
+                        store.setState({ powerups: message.powerups });
+                    }
+                    break;
+                }
                 case 'lobbyCountdown':
                 case 'gameCountdown':
                     store.setState({ countdown: message.seconds });
                     break;
+                case 'ChatHistory':
+                    store.setState({ chatMessages: message.messages.map(m => ({ player: m.name, message: m.content, senderIndex: m.senderIndex, color: m.color, kind: m.kind, to: m.to })) });
+                    break;
                 case 'CM':
                     const { chatMessages } = store.getState();
-                    store.setState({ chatMessages: [...chatMessages, { player: message.name, message: message.content, senderIndex: message.senderIndex, color: message.color }] });
+                    store.setState({ chatMessages: [...chatMessages, { player: message.name, message: message.content, senderIndex: message.senderIndex, color: message.color, kind: message.kind, to: message.to }] });
                     break;
                 case 'playerUpdate':
                     store.setState({ gameData: { ...store.getState().gameData, players: message.players, panel: message.panel } });
@@ -90,6 +154,21 @@ export function handleWebSocket() {
                 case 'AddPowerup':
                     store.setState({ powerups: [...store.getState().powerups, message.powerup] });
                     break;
+                case 'KillFeed':
+                    store.setState({ killFeed: [...store.getState().killFeed, message].slice(-5) });
+                    break;
+                case 'Block':
+                    // Sudden death: the cell is now a wall, whatever was on it is gone
+                    if (gameData && gameData.panel) {
+                        const newPanel = [...gameData.panel];
+                        newPanel[message.row] = [...newPanel[message.row]];
+                        newPanel[message.row][message.col] = 'W';
+                        store.setState({
+                            gameData: { ...gameData, panel: newPanel },
+                            powerups: store.getState().powerups.filter(p => p.row !== message.row || p.column !== message.col),
+                        });
+                    }
+                    break;
                 case 'RemovePowerup':
                     store.setState({ powerups: store.getState().powerups.filter(p => p.row !== message.row || p.column !== message.column) });
                     break;
@@ -198,7 +277,25 @@ export function handleWebSocket() {
                 case 'BA':
                     if (gameData && gameData.panel) {
                         const newPanel = [...gameData.panel];
-                        newPanel[message.R][message.C] = 'B';
+                        // Remote bombs wait for a detonate and are drawn differently
+                        newPanel[message.R][message.C] = message.RM ? 'RB' : 'B';
+                        bombCells.set(message.ID, [message.R, message.C]);
+                        store.setState({ gameData: { ...gameData, panel: newPanel } });
+                    }
+                    break;
+                case 'BM': // Kicked bomb sliding
+                    if (gameData && gameData.panel) {
+                        const [row, col] = bombCells.get(message.ID) || [message.R, message.C];
+                        if (row === message.R && col === message.C) {
+                            break;
+                        }
+                        const newPanel = gameData.panel.map(r => [...r]);
+                        const bomb = newPanel[row][col] === 'RB' ? 'RB' : 'B';
+                        if (newPanel[row][col] === bomb) {
+                            newPanel[row][col] = '';
+                        }
+                        newPanel[message.R][message.C] = bomb;
+                        bombCells.set(message.ID, [message.R, message.C]);
                         store.setState({ gameData: { ...gameData, panel: newPanel } });
                     }
                     break;
@@ -225,7 +322,7 @@ export function handleWebSocket() {
                     break;
             }
         }
-    };
+    }
 
     ws.onclose = (event) => {
         console.log('Websocket connection closed for player ')